@@ -2,17 +2,37 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/rpc"
 	"log"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/keystore"
 )
 
+// balance prints the lamports held by the address or keystore account given as argument, or by the configured fee payer.
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	address := keystore.Address(flag.Arg(0))
+	if address == "" {
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			log.Fatal(err)
+		}
+		address = feePayer.PublicKey.ToBase58()
+	}
+
 	balance, err := c.GetBalance(
 		context.TODO(),
-		"HcNCxoni2Ln5si48s1w8r5TRVH296RQ1MzKeM9FctdPg",
+		address,
 	)
 	if err != nil {
 		log.Fatalf("get balance, err: %v", err)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/blocto/solana-go-sdk/types"
+
+	"solana-starter/pkg/keypair"
+)
+
+// keygen creates a keypair file in the solana-keygen format, from a new mnemonic or an existing signer,
+// and prints the key in the other formats. When writing a file it prints only the public key,
+// so no secret ends up in the terminal scrollback.
+func main() {
+	outfile := flag.String("o", "", "keypair file to write, e.g. ~/.config/solana/id.json")
+	from := flag.String("from", "", "signer to convert instead of generating one: a file, base58:<key>, mnemonic:<words> or -")
+	derivation := flag.String("derivation", "", "BIP44 path for a new mnemonic, e.g. "+keypair.DefaultDerivationPath+"; solana-keygen derivation when empty")
+	flag.Parse()
+
+	var account types.Account
+	var err error
+	if *from != "" {
+		account, err = keypair.LoadSigner(*from)
+	} else {
+		var mnemonic string
+		mnemonic, err = keypair.NewMnemonic()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *outfile == "" {
+			fmt.Println("mnemonic:", mnemonic)
+		}
+		account, err = keypair.FromMnemonic(mnemonic, "", *derivation)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *outfile == "" {
+		fmt.Println("public key:", account.PublicKey.ToBase58())
+		fmt.Println("base58 private key:", keypair.EncodeBase58(account))
+		fmt.Println("json:", string(keypair.EncodeJSON(account)))
+		return
+	}
+	if err := keypair.WriteFile(*outfile, account); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			log.Fatalf("%s already exists, refusing to overwrite it", *outfile)
+		}
+		log.Fatal(err)
+	}
+	fmt.Println(account.PublicKey.ToBase58())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/blocto/solana-go-sdk/types"
+
+	"solana-starter/pkg/keypair"
+	"solana-starter/pkg/keystore"
+)
+
+const usage = `usage: keystore <command> [arguments]
+
+  list                    names and public keys of the stored accounts
+  new <name>              generate an account and store it under name
+  import <name> <signer>  store a keypair file, base58:<key>, mnemonic:<words> or - under name
+  export <name> <file>    write an account to a solana-keygen JSON keypair file
+  remove <name>           delete an account
+
+The keystore is $` + keystore.EnvPath + ` or ` + keystore.DefaultPath + `; the passphrase is read from
+$` + keystore.EnvPassphrase + ` or prompted for. Commands then take the name as signer, e.g. -fee-payer alice.`
+
+func main() {
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	ks, err := keystore.OpenDefault()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch command, name := flag.Arg(0), flag.Arg(1); {
+	case command == "list" && flag.NArg() == 1:
+		for _, name := range ks.Names() {
+			publicKey, _ := ks.PublicKey(name)
+			fmt.Printf("%-20s %s\n", name, publicKey.ToBase58())
+		}
+	case command == "new" && flag.NArg() == 2:
+		mnemonic, err := keypair.NewMnemonic()
+		if err != nil {
+			log.Fatal(err)
+		}
+		account, err := keypair.FromMnemonic(mnemonic, "", "")
+		if err != nil {
+			log.Fatal(err)
+		}
+		put(ks, name, account)
+		fmt.Printf("stored %s: %s\n", name, account.PublicKey.ToBase58())
+		fmt.Println("write down the mnemonic to recover it with import mnemonic:<words>:", mnemonic)
+	case command == "import" && flag.NArg() == 3:
+		account, err := keypair.LoadSigner(flag.Arg(2))
+		if err != nil {
+			log.Fatal(err)
+		}
+		put(ks, name, account)
+		fmt.Printf("stored %s: %s\n", name, account.PublicKey.ToBase58())
+	case command == "export" && flag.NArg() == 3:
+		unlock(ks)
+		account, err := ks.Get(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := keypair.WriteFile(flag.Arg(2), account); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("wrote %s to %s\n", name, flag.Arg(2))
+	case command == "remove" && flag.NArg() == 2:
+		if err := ks.Remove(name); err != nil {
+			log.Fatal(err)
+		}
+		if err := ks.Save(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("removed", name)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// put stores account under name and saves the keystore.
+func put(ks *keystore.Keystore, name string, account types.Account) {
+	unlock(ks)
+	if err := ks.Put(name, account); err != nil {
+		log.Fatal(err)
+	}
+	if err := ks.Save(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// unlock asks for the passphrase, twice when it is chosen for a new keystore.
+func unlock(ks *keystore.Keystore) {
+	passphrase, err := keystore.Passphrase(fmt.Sprintf("passphrase for %s: ", ks.Path()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(ks.Names()) == 0 && os.Getenv(keystore.EnvPassphrase) == "" {
+		again, err := keystore.Passphrase("repeat passphrase: ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if again != passphrase {
+			log.Fatal("passphrases do not match")
+		}
+	}
+	if err := ks.Unlock(passphrase); err != nil {
+		log.Fatal(err)
+	}
+}
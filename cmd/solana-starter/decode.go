@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+)
+
+// decodeResult is the decoded transaction; with --json it is the envelope as is.
+type decodeResult struct {
+	*decoder.Envelope
+}
+
+func (r decodeResult) text() string {
+	e := r.Envelope
+	var b strings.Builder
+	fmt.Fprintf(&b, "signature: %s\n", e.Signature)
+	fmt.Fprintf(&b, "slot:      %d\n", e.Slot)
+	fmt.Fprintf(&b, "fee payer: %s\n", e.FeePayer)
+	fmt.Fprintf(&b, "fee:       %s SOL", uiAmount(e.Fee, solDecimals))
+	if e.Fees != nil && e.Fees.PriorityFee > 0 {
+		fmt.Fprintf(&b, " (%s SOL priority)", e.Fees.UiPriorityFee)
+	}
+	if e.ComputeUnitsConsumed != nil {
+		fmt.Fprintf(&b, "\ncompute:   %d units", *e.ComputeUnitsConsumed)
+	}
+	fmt.Fprintf(&b, "\nstatus:    %s", e.Status)
+	if e.Failed() {
+		fmt.Fprintf(&b, " (%v), the events below took no effect", e.Err)
+	}
+	for _, event := range e.Events {
+		fmt.Fprintf(&b, "\n%s: %+v", event.EventType(), event)
+	}
+	return b.String()
+}
+
+func setupDecodeTx(fs *flag.FlagSet) runFunc {
+	programs := fs.String("programs", "", "JSON file naming programs, to label the instructions they invoke")
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) != 1 {
+			return nil, usagef("expected a transaction signature")
+		}
+		var opts []decoder.Option
+		if *programs != "" {
+			registry, err := decoder.LoadProgramRegistry(*programs)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, decoder.WithProgramRegistry(registry))
+		}
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		envelope, err := decoder.New(c, opts...).DecodeSignatureEnvelope(ctx, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if envelope.Failed() {
+			return decodeResult{envelope}, errTxFailed
+		}
+		return decodeResult{envelope}, nil
+	}
+}
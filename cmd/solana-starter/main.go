@@ -0,0 +1,199 @@
+// solana-starter runs the examples of this repository as subcommands of one binary.
+//
+// Every subcommand takes the config flags of package config, prints text or, with --json, one JSON
+// document, and exits with one of the codes below.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+
+	"solana-starter/pkg/config"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0
+	exitError    = 1 // the command failed, e.g. the RPC node could not be reached
+	exitUsage    = 2 // invalid flags or arguments
+	exitTxFailed = 3 // the transaction was processed but failed on chain
+)
+
+var (
+	// errTxFailed is returned for transactions that failed on chain.
+	errTxFailed = errors.New("transaction failed")
+	// errNotConfirmed is returned for transactions sent but not seen confirmed, which may still land.
+	errNotConfirmed = errors.New("transaction not confirmed")
+)
+
+// usageError is an invalid invocation of a subcommand.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// result is what a subcommand prints: JSON-encoded with --json, else its text.
+type result interface {
+	text() string
+}
+
+// runFunc runs a subcommand once its flags are parsed, with its positional arguments.
+type runFunc func(ctx context.Context, cfg *config.Config, args []string) (result, error)
+
+// command is a subcommand.
+type command struct {
+	name    string
+	args    string // positional arguments, for the usage line
+	summary string
+	// setup registers the subcommand's own flags and returns the function running it.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+var commands = []command{
+	{"balance", "[address or keystore name]", "print the SOL balance of an account, the fee payer by default", setupBalance},
+	{"airdrop", "[address or keystore name]", "request SOL from the devnet or testnet faucet", setupAirdrop},
+	{"transfer-sol", "<recipient> <amount SOL>", "send SOL from the authority", setupTransferSOL},
+	{"create-mint", "", "create a token mint controlled by the authority", setupCreateMint},
+	{"create-ata", "[owner]", "create the associated token account of an owner for the mint", setupCreateATA},
+	{"mint-to", "<amount> [owner]", "mint tokens to an owner's associated token account", setupMintTo},
+	{"transfer-token", "<recipient> <amount>", "send tokens of the mint from the authority", setupTransferToken},
+	{"token-info", "[mint]", "print the supply, authorities and metadata of a mint", setupTokenInfo},
+	{"set-metadata", "[mint]", "create or update the Metaplex metadata of a mint", setupSetMetadata},
+	{"decode-tx", "<signature>", "decode the token events, fees and status of a transaction", setupDecodeTx},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: solana-starter %s [flags] %s\n\n%s\n\nflags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	jsonOutput := fs.Bool("json", false, "print the result as JSON")
+	flags := config.Bind(fs, config.Config{})
+	runCmd := cmd.setup(fs)
+
+	positional, err := parseInterspersed(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	cfg, err := flags.Load()
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := runCmd(ctx, cfg, positional)
+
+	var usage usageError
+	switch {
+	case errors.As(err, &usage):
+		fmt.Fprintln(stderr, "error:", err)
+		fs.Usage()
+		return exitUsage
+	case err != nil && !errors.Is(err, errTxFailed) && !errors.Is(err, errNotConfirmed):
+		fmt.Fprintln(stderr, "error:", err)
+		return exitError
+	}
+
+	// A failed or unconfirmed transaction still has a result worth printing, its signature above all
+	if res != nil {
+		if *jsonOutput {
+			enc := json.NewEncoder(stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(res); err != nil {
+				fmt.Fprintln(stderr, "error:", err)
+				return exitError
+			}
+		} else {
+			fmt.Fprintln(stdout, res.text())
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		if errors.Is(err, errNotConfirmed) {
+			return exitError
+		}
+		return exitTxFailed
+	}
+	return exitOK
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// parseInterspersed parses flags given before, between or after the positional arguments,
+// so `balance alice --json` works as well as `balance --json alice`.
+// Everything after -- is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, rest = args[:i], args[i+1:]
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: solana-starter <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-15s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run solana-starter <command> -h for its flags. Every command takes --json and the config flags")
+	fmt.Fprintln(w, "-cluster, -rpc-url, -rpc-fallback-urls, -rpc-rate-limit, -fee-payer, -authority, -mint and -config.")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "exit codes: %d ok, %d error, %d invalid usage, %d transaction failed on chain\n",
+		exitOK, exitError, exitUsage, exitTxFailed)
+}
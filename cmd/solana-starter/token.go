@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
+)
+
+type createMintResult struct {
+	Mint            string `json:"mint"`
+	Decimals        uint8  `json:"decimals"`
+	MintAuthority   string `json:"mintAuthority"`
+	FreezeAuthority string `json:"freezeAuthority,omitempty"`
+	txResult
+}
+
+func (r createMintResult) text() string {
+	return fmt.Sprintf("mint:      %s\n%s", r.Mint, r.txResult.text())
+}
+
+func setupCreateMint(fs *flag.FlagSet) runFunc {
+	decimals := fs.Uint("decimals", 9, "decimals of the token")
+	freezeAuthority := fs.String("freeze-authority", "", "address or keystore name allowed to freeze token accounts (default none)")
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) != 0 {
+			return nil, usagef("expected no arguments")
+		}
+		if *decimals > 255 {
+			return nil, usagef("invalid decimals %d", *decimals)
+		}
+		var freeze *common.PublicKey
+		if *freezeAuthority != "" {
+			address, err := resolveAddress(*freezeAuthority)
+			if err != nil {
+				return nil, err
+			}
+			freeze = &address
+		}
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			return nil, err
+		}
+		authority, err := cfg.AuthorityAccount()
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		mint := types.NewAccount()
+		instructions, err := tokenops.CreateMint(ctx, c, tokenops.CreateMintParam{
+			Payer:           feePayer.PublicKey,
+			Mint:            mint.PublicKey,
+			MintAuthority:   authority.PublicKey,
+			FreezeAuthority: freeze,
+			Decimals:        uint8(*decimals),
+		})
+		if err != nil {
+			return nil, err
+		}
+		tx, err := send(ctx, c, cfg, instructions, mint)
+		if tx.Signature == "" {
+			return nil, err
+		}
+		res := createMintResult{
+			Mint:          mint.PublicKey.ToBase58(),
+			Decimals:      uint8(*decimals),
+			MintAuthority: authority.PublicKey.ToBase58(),
+			txResult:      tx,
+		}
+		if freeze != nil {
+			res.FreezeAuthority = freeze.ToBase58()
+		}
+		return res, err
+	}
+}
+
+type createATAResult struct {
+	Owner        string `json:"owner"`
+	Mint         string `json:"mint"`
+	TokenAccount string `json:"tokenAccount"`
+	Existed      bool   `json:"existed"`
+	*txResult           // nil if the account existed
+}
+
+func (r createATAResult) text() string {
+	if r.Existed {
+		return fmt.Sprintf("token account %s already exists", r.TokenAccount)
+	}
+	return fmt.Sprintf("token account: %s\n%s", r.TokenAccount, r.txResult.text())
+}
+
+func setupCreateATA(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most one owner")
+		}
+		mint, err := configuredMint(cfg)
+		if err != nil {
+			return nil, err
+		}
+		owner, err := ownerOrAuthority(cfg, args)
+		if err != nil {
+			return nil, err
+		}
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			return nil, err
+		}
+		ata, create, err := tokenops.CreateATA(feePayer.PublicKey, owner, mint)
+		if err != nil {
+			return nil, err
+		}
+		res := createATAResult{Owner: owner.ToBase58(), Mint: mint.ToBase58(), TokenAccount: ata.ToBase58()}
+
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		account, err := c.GetAccountInfo(ctx, ata.ToBase58())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token account: %w", err)
+		}
+		if account.Owner != (common.PublicKey{}) {
+			res.Existed = true
+			return res, nil
+		}
+		tx, err := send(ctx, c, cfg, []types.Instruction{create})
+		if tx.Signature == "" {
+			return nil, err
+		}
+		res.txResult = &tx
+		return res, err
+	}
+}
+
+type mintToResult struct {
+	Mint         string `json:"mint"`
+	Owner        string `json:"owner"`
+	TokenAccount string `json:"tokenAccount"`
+	Amount       uint64 `json:"amount"` // base units
+	UiAmount     string `json:"uiAmount"`
+	txResult
+}
+
+func (r mintToResult) text() string {
+	return fmt.Sprintf("minted %s to %s\n%s", r.UiAmount, r.TokenAccount, r.txResult.text())
+}
+
+func setupMintTo(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) < 1 || len(args) > 2 {
+			return nil, usagef("expected an amount and at most one owner")
+		}
+		mint, err := configuredMint(cfg)
+		if err != nil {
+			return nil, err
+		}
+		owner, err := ownerOrAuthority(cfg, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		mintAccount, err := tokenops.GetMint(ctx, c, mint)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount(args[0], mintAccount.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			return nil, err
+		}
+		authority, err := cfg.AuthorityAccount()
+		if err != nil {
+			return nil, err
+		}
+		ata, err := tokenops.ATA(owner, mint)
+		if err != nil {
+			return nil, err
+		}
+		instructions, err := tokenops.MintTo(tokenops.MintToParam{
+			Payer:     feePayer.PublicKey,
+			Owner:     owner,
+			Mint:      mint,
+			Authority: authority.PublicKey,
+			Amount:    amount,
+			Decimals:  mintAccount.Decimals,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := send(ctx, c, cfg, instructions, authority)
+		if tx.Signature == "" {
+			return nil, err
+		}
+		return mintToResult{
+			Mint:         mint.ToBase58(),
+			Owner:        owner.ToBase58(),
+			TokenAccount: ata.ToBase58(),
+			Amount:       amount,
+			UiAmount:     uiAmount(amount, mintAccount.Decimals),
+			txResult:     tx,
+		}, err
+	}
+}
+
+type transferTokenResult struct {
+	Mint        string `json:"mint"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Source      string `json:"source"`      // token account of From
+	Destination string `json:"destination"` // token account of To
+	Amount      uint64 `json:"amount"`      // base units
+	UiAmount    string `json:"uiAmount"`
+	txResult
+}
+
+func (r transferTokenResult) text() string {
+	return fmt.Sprintf("sent %s from %s to %s\n%s", r.UiAmount, r.From, r.To, r.txResult.text())
+}
+
+func setupTransferToken(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) != 2 {
+			return nil, usagef("expected a recipient and an amount")
+		}
+		mint, err := configuredMint(cfg)
+		if err != nil {
+			return nil, err
+		}
+		to, err := resolveAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		mintAccount, err := tokenops.GetMint(ctx, c, mint)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := parseAmount(args[1], mintAccount.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			return nil, err
+		}
+		from, err := cfg.AuthorityAccount()
+		if err != nil {
+			return nil, err
+		}
+		source, err := tokenops.ATA(from.PublicKey, mint)
+		if err != nil {
+			return nil, err
+		}
+		destination, err := tokenops.ATA(to, mint)
+		if err != nil {
+			return nil, err
+		}
+		instructions, err := tokenops.Transfer(tokenops.TransferParam{
+			Payer:    feePayer.PublicKey,
+			From:     from.PublicKey,
+			To:       to,
+			Mint:     mint,
+			Amount:   amount,
+			Decimals: mintAccount.Decimals,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		tx, err := send(ctx, c, cfg, instructions, from)
+		if tx.Signature == "" {
+			return nil, err
+		}
+		return transferTokenResult{
+			Mint:        mint.ToBase58(),
+			From:        from.PublicKey.ToBase58(),
+			To:          to.ToBase58(),
+			Source:      source.ToBase58(),
+			Destination: destination.ToBase58(),
+			Amount:      amount,
+			UiAmount:    uiAmount(amount, mintAccount.Decimals),
+			txResult:    tx,
+		}, err
+	}
+}
+
+type tokenInfoResult struct {
+	Mint            string        `json:"mint"`
+	Program         string        `json:"program"`
+	Decimals        uint8         `json:"decimals"`
+	Supply          uint64        `json:"supply"` // base units
+	UiSupply        string        `json:"uiSupply"`
+	MintAuthority   string        `json:"mintAuthority,omitempty"`   // empty once minting is disabled
+	FreezeAuthority string        `json:"freezeAuthority,omitempty"` // empty if accounts cannot be frozen
+	Metadata        *metadataInfo `json:"metadata,omitempty"`        // nil without Metaplex metadata
+}
+
+type metadataInfo struct {
+	Address         string `json:"address"`
+	Name            string `json:"name"`
+	Symbol          string `json:"symbol"`
+	URI             string `json:"uri"`
+	UpdateAuthority string `json:"updateAuthority"`
+	IsMutable       bool   `json:"isMutable"`
+}
+
+func (r tokenInfoResult) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "mint:             %s\n", r.Mint)
+	fmt.Fprintf(&b, "program:          %s\n", r.Program)
+	fmt.Fprintf(&b, "decimals:         %d\n", r.Decimals)
+	fmt.Fprintf(&b, "supply:           %s\n", r.UiSupply)
+	fmt.Fprintf(&b, "mint authority:   %s\n", orNone(r.MintAuthority))
+	fmt.Fprintf(&b, "freeze authority: %s", orNone(r.FreezeAuthority))
+	if m := r.Metadata; m != nil {
+		fmt.Fprintf(&b, "\nname:             %s\n", m.Name)
+		fmt.Fprintf(&b, "symbol:           %s\n", m.Symbol)
+		fmt.Fprintf(&b, "uri:              %s\n", m.URI)
+		fmt.Fprintf(&b, "update authority: %s", m.UpdateAuthority)
+	}
+	return b.String()
+}
+
+func setupTokenInfo(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most one mint")
+		}
+		var mint common.PublicKey
+		var err error
+		if len(args) == 1 {
+			mint, err = resolveAddress(args[0])
+		} else {
+			mint, err = configuredMint(cfg)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		account, err := c.GetAccountInfo(ctx, mint.ToBase58())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mint: %w", err)
+		}
+		mintAccount, err := tokenops.ParseMint(mint, account)
+		if err != nil {
+			return nil, err
+		}
+		res := tokenInfoResult{
+			Mint:     mint.ToBase58(),
+			Program:  account.Owner.ToBase58(),
+			Decimals: mintAccount.Decimals,
+			Supply:   mintAccount.Supply,
+			UiSupply: uiAmount(mintAccount.Supply, mintAccount.Decimals),
+		}
+		if mintAccount.MintAuthority != nil {
+			res.MintAuthority = mintAccount.MintAuthority.ToBase58()
+		}
+		if mintAccount.FreezeAuthority != nil {
+			res.FreezeAuthority = mintAccount.FreezeAuthority.ToBase58()
+		}
+
+		metadataAddress, metadata, err := tokenops.GetMetadata(ctx, c, mint)
+		if err != nil {
+			return nil, err
+		}
+		if metadata != nil {
+			res.Metadata = &metadataInfo{
+				Address:         metadataAddress.ToBase58(),
+				Name:            tokenops.TrimPadding(metadata.Data.Name),
+				Symbol:          tokenops.TrimPadding(metadata.Data.Symbol),
+				URI:             tokenops.TrimPadding(metadata.Data.Uri),
+				UpdateAuthority: metadata.UpdateAuthority.ToBase58(),
+				IsMutable:       metadata.IsMutable,
+			}
+		}
+		return res, nil
+	}
+}
+
+type setMetadataResult struct {
+	Mint     string `json:"mint"`
+	Metadata string `json:"metadata"` // the metadata account
+	Created  bool   `json:"created"`  // false if existing metadata was updated
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	URI      string `json:"uri"`
+	txResult
+}
+
+func (r setMetadataResult) text() string {
+	action := "updated"
+	if r.Created {
+		action = "created"
+	}
+	return fmt.Sprintf("%s metadata %s of %s\n%s", action, r.Metadata, r.Mint, r.txResult.text())
+}
+
+func setupSetMetadata(fs *flag.FlagSet) runFunc {
+	name := fs.String("name", "", "token name (required to create metadata, kept when updating if empty)")
+	symbol := fs.String("symbol", "", "token symbol (required to create metadata, kept when updating if empty)")
+	uri := fs.String("uri", "", "URI of the off-chain JSON metadata (kept when updating if empty)")
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most one mint")
+		}
+		var mint common.PublicKey
+		var err error
+		if len(args) == 1 {
+			mint, err = resolveAddress(args[0])
+		} else {
+			mint, err = configuredMint(cfg)
+		}
+		if err != nil {
+			return nil, err
+		}
+		feePayer, err := cfg.FeePayerAccount()
+		if err != nil {
+			return nil, err
+		}
+		authority, err := cfg.AuthorityAccount()
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		metadataAddress, metadata, err := tokenops.GetMetadata(ctx, c, mint)
+		if err != nil {
+			return nil, err
+		}
+		instruction, data, err := tokenops.SetMetadata(metadata, tokenops.SetMetadataParam{
+			Mint:      mint,
+			Payer:     feePayer.PublicKey,
+			Authority: authority.PublicKey,
+			Name:      *name,
+			Symbol:    *symbol,
+			URI:       *uri,
+		})
+		switch {
+		case errors.Is(err, tokenops.ErrIncompleteMetadata):
+			return nil, usagef("-name and -symbol are required to create metadata")
+		case errors.Is(err, tokenops.ErrNothingToUpdate):
+			return nil, usagef("nothing to update: set -name, -symbol or -uri")
+		case err != nil:
+			return nil, err
+		}
+
+		tx, err := send(ctx, c, cfg, []types.Instruction{instruction}, authority)
+		if tx.Signature == "" {
+			return nil, err
+		}
+		return setMetadataResult{
+			Mint:     mint.ToBase58(),
+			Metadata: metadataAddress.ToBase58(),
+			Created:  metadata == nil,
+			Name:     data.Name,
+			Symbol:   data.Symbol,
+			URI:      data.Uri,
+			txResult: tx,
+		}, err
+	}
+}
+
+// configuredMint returns the mint set by -mint, the environment or the config file.
+func configuredMint(cfg *config.Config) (common.PublicKey, error) {
+	if cfg.Mint == "" {
+		return common.PublicKey{}, usagef("no mint configured: set -mint, %s or mint in the config file", config.EnvMint)
+	}
+	return resolveAddress(cfg.Mint)
+}
+
+// ownerOrAuthority resolves the optional owner argument, defaulting to the authority.
+func ownerOrAuthority(cfg *config.Config, args []string) (common.PublicKey, error) {
+	if len(args) == 1 {
+		return resolveAddress(args[0])
+	}
+	authority, err := cfg.AuthorityAccount()
+	if err != nil {
+		return common.PublicKey{}, err
+	}
+	return authority.PublicKey, nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
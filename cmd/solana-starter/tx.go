@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/shopspring/decimal"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/keystore"
+)
+
+const (
+	lamportsPerSOL  = 1_000_000_000
+	solDecimals     = 9
+	confirmInterval = 500 * time.Millisecond
+	// confirmTimeout outlasts the ~60 seconds a blockhash stays valid
+	confirmTimeout = 90 * time.Second
+)
+
+// txResult is the outcome of a transaction a subcommand sent.
+type txResult struct {
+	Signature string `json:"signature"`
+	Explorer  string `json:"explorer"`
+	Slot      uint64 `json:"slot"`
+	Err       any    `json:"err,omitempty"` // the on-chain error of a failed transaction
+}
+
+func (r txResult) text() string {
+	s := fmt.Sprintf("signature: %s\nexplorer:  %s", r.Signature, r.Explorer)
+	switch {
+	case r.Err != nil:
+		s += fmt.Sprintf("\nfailed:    %v", r.Err)
+	case r.Slot == 0:
+		// Only confirmed transactions have a slot
+		s += "\nstatus:    not confirmed"
+	}
+	return s
+}
+
+// send signs instructions with the fee payer and signers, sends them and waits for confirmation.
+// A transaction that fails on chain is returned with errTxFailed, and one not confirmed in time with errNotConfirmed.
+func send(ctx context.Context, c *client.Client, cfg *config.Config, instructions []types.Instruction, signers ...types.Account) (txResult, error) {
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		return txResult{}, err
+	}
+	blockhash, err := c.GetLatestBlockhash(ctx)
+	if err != nil {
+		return txResult{}, fmt.Errorf("failed to get recent blockhash: %w", err)
+	}
+
+	// The fee payer is often the authority too, and must sign once
+	all := []types.Account{feePayer}
+	for _, signer := range signers {
+		if signer.PublicKey != feePayer.PublicKey {
+			all = append(all, signer)
+		}
+	}
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Signers: all,
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: blockhash.Blockhash,
+			Instructions:    instructions,
+		}),
+	})
+	if err != nil {
+		return txResult{}, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	sig, err := c.SendTransaction(ctx, tx)
+	if err != nil {
+		return txResult{}, fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return confirm(ctx, c, cfg, sig)
+}
+
+// confirm polls the status of sig until it is confirmed, failed, or confirmTimeout passes.
+// The result carries the signature in every case, so it can be looked up later.
+func confirm(ctx context.Context, c *client.Client, cfg *config.Config, sig string) (txResult, error) {
+	res := txResult{Signature: sig, Explorer: cfg.ExplorerTxURL(sig)}
+	ctx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	ticker := time.NewTicker(confirmInterval)
+	defer ticker.Stop()
+	for {
+		status, err := c.GetSignatureStatus(ctx, sig)
+		if err == nil && status != nil && status.ConfirmationStatus != nil &&
+			*status.ConfirmationStatus != rpc.CommitmentProcessed {
+			res.Slot = status.Slot
+			if status.Err != nil {
+				res.Err = status.Err
+				return res, errTxFailed
+			}
+			return res, nil
+		}
+		select {
+		case <-ctx.Done():
+			return res, fmt.Errorf("%w: %s: %w", errNotConfirmed, sig, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolveAddress parses an address argument, which may also name a keystore account.
+func resolveAddress(arg string) (common.PublicKey, error) {
+	address, err := config.PublicKey(keystore.Address(arg))
+	if err != nil {
+		return common.PublicKey{}, usagef("%v: expected an address or keystore name", err)
+	}
+	return address, nil
+}
+
+// parseAmount converts a UI amount such as "1.5" to base units of a token with decimals.
+func parseAmount(amount string, decimals uint8) (uint64, error) {
+	value, err := decimal.NewFromString(amount)
+	if err != nil || value.Sign() <= 0 {
+		return 0, usagef("invalid amount %q", amount)
+	}
+	units := value.Shift(int32(decimals))
+	if !units.IsInteger() {
+		return 0, usagef("amount %s has more than %d decimals", amount, decimals)
+	}
+	if !units.BigInt().IsUint64() {
+		return 0, usagef("amount %s is too large", amount)
+	}
+	return units.BigInt().Uint64(), nil
+}
+
+// uiAmount formats base units of a token with decimals.
+func uiAmount(units uint64, decimals uint8) string {
+	return decimal.NewFromBigInt(new(big.Int).SetUint64(units), -int32(decimals)).String()
+}
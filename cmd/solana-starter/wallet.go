@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/types"
+
+	"solana-starter/pkg/config"
+)
+
+type balanceResult struct {
+	Address  string `json:"address"`
+	Lamports uint64 `json:"lamports"`
+	SOL      string `json:"sol"`
+}
+
+func (r balanceResult) text() string {
+	return fmt.Sprintf("%s SOL (%d lamports)", r.SOL, r.Lamports)
+}
+
+func setupBalance(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most one address")
+		}
+		address, err := addressOrFeePayer(cfg, args)
+		if err != nil {
+			return nil, err
+		}
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		lamports, err := c.GetBalance(ctx, address.ToBase58())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		return balanceResult{Address: address.ToBase58(), Lamports: lamports, SOL: uiAmount(lamports, solDecimals)}, nil
+	}
+}
+
+type airdropResult struct {
+	Address  string `json:"address"`
+	Lamports uint64 `json:"lamports"`
+	txResult
+}
+
+func (r airdropResult) text() string {
+	return fmt.Sprintf("airdropped %s SOL to %s\n%s", uiAmount(r.Lamports, solDecimals), r.Address, r.txResult.text())
+}
+
+func setupAirdrop(fs *flag.FlagSet) runFunc {
+	amount := fs.String("amount", "1", "SOL to request")
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) > 1 {
+			return nil, usagef("expected at most one address")
+		}
+		if cfg.Cluster == config.Mainnet {
+			return nil, usagef("there is no faucet on mainnet")
+		}
+		address, err := addressOrFeePayer(cfg, args)
+		if err != nil {
+			return nil, err
+		}
+		lamports, err := parseAmount(*amount, solDecimals)
+		if err != nil {
+			return nil, err
+		}
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		sig, err := c.RequestAirdrop(ctx, address.ToBase58(), lamports)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request airdrop: %w", err)
+		}
+		tx, err := confirm(ctx, c, cfg, sig)
+		return airdropResult{Address: address.ToBase58(), Lamports: lamports, txResult: tx}, err
+	}
+}
+
+type transferSOLResult struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Lamports uint64 `json:"lamports"`
+	txResult
+}
+
+func (r transferSOLResult) text() string {
+	return fmt.Sprintf("sent %s SOL from %s to %s\n%s", uiAmount(r.Lamports, solDecimals), r.From, r.To, r.txResult.text())
+}
+
+func setupTransferSOL(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, cfg *config.Config, args []string) (result, error) {
+		if len(args) != 2 {
+			return nil, usagef("expected a recipient and an amount")
+		}
+		to, err := resolveAddress(args[0])
+		if err != nil {
+			return nil, err
+		}
+		lamports, err := parseAmount(args[1], solDecimals)
+		if err != nil {
+			return nil, err
+		}
+		from, err := cfg.AuthorityAccount()
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := cfg.Client()
+		if err != nil {
+			return nil, err
+		}
+		tx, err := send(ctx, c, cfg, []types.Instruction{
+			system.Transfer(system.TransferParam{
+				From:   from.PublicKey,
+				To:     to,
+				Amount: lamports,
+			}),
+		}, from)
+		if tx.Signature == "" {
+			return nil, err
+		}
+		return transferSOLResult{From: from.PublicKey.ToBase58(), To: to.ToBase58(), Lamports: lamports, txResult: tx}, err
+	}
+}
+
+// addressOrFeePayer resolves the optional address argument, defaulting to the fee payer.
+func addressOrFeePayer(cfg *config.Config, args []string) (common.PublicKey, error) {
+	if len(args) == 1 {
+		return resolveAddress(args[0])
+	}
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		return common.PublicKey{}, err
+	}
+	return feePayer.PublicKey, nil
+}
@@ -0,0 +1,345 @@
+// Package config resolves the cluster, RPC endpoint, signer keypairs and default mint the commands run with.
+//
+// Settings are read, lowest precedence first, from built-in defaults, a YAML file, SOLANA_STARTER_*
+// environment variables and command-line flags, so one binary works on localnet, devnet and mainnet.
+// Switching to another cluster drops the endpoints given with lower precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/mr-tron/base58"
+	"gopkg.in/yaml.v3"
+
+	"solana-starter/pkg/keypair"
+	"solana-starter/pkg/pathutil"
+	"solana-starter/pkg/rpcclient"
+)
+
+// Clusters.
+const (
+	Localnet = "localnet"
+	Devnet   = "devnet"
+	Testnet  = "testnet"
+	Mainnet  = "mainnet"
+)
+
+// Environment variables read by Load.
+const (
+	EnvConfig    = "SOLANA_STARTER_CONFIG"
+	EnvCluster   = "SOLANA_STARTER_CLUSTER"
+	EnvRPCURL    = "SOLANA_STARTER_RPC_URL"
+	EnvWSURL     = "SOLANA_STARTER_WS_URL"
+	EnvFeePayer  = "SOLANA_STARTER_FEE_PAYER"
+	EnvAuthority = "SOLANA_STARTER_AUTHORITY"
+	EnvMint      = "SOLANA_STARTER_MINT"
+	// EnvRPCFallbackURLs is a comma-separated list
+	EnvRPCFallbackURLs = "SOLANA_STARTER_RPC_FALLBACK_URLS"
+	EnvRPCRateLimit    = "SOLANA_STARTER_RPC_RATE_LIMIT"
+)
+
+// DefaultConfigPath is the YAML file read when neither -config nor SOLANA_STARTER_CONFIG names one.
+// It may be missing.
+const DefaultConfigPath = "~/.config/solana-starter/config.yaml"
+
+// DefaultKeypairPath is the keypair the Solana CLI creates, used as fee payer unless another is configured.
+const DefaultKeypairPath = "~/.config/solana/id.json"
+
+// Config is the resolved configuration of a command.
+type Config struct {
+	Cluster   string `yaml:"cluster"`
+	RPCURL    string `yaml:"rpc_url"`   // overrides the cluster's public endpoint
+	WSURL     string `yaml:"ws_url"`    // WebSocket endpoint, derived from RPCURL when empty
+	FeePayer  string `yaml:"fee_payer"` // signer paying fees: a keypair file, keystore name or keypair.LoadSigner source
+	Authority string `yaml:"authority"` // signer owning tokens and minting, the fee payer when empty
+	Mint      string `yaml:"mint"`      // default mint address
+	// RPCFallbackURLs are failed over to, in order, when the RPC endpoint is down or rate limits us
+	RPCFallbackURLs []string `yaml:"rpc_fallback_urls"`
+	// RPCRateLimit caps requests per second to each endpoint; 0 keeps the public endpoints' limits and leaves others unlimited
+	RPCRateLimit float64 `yaml:"rpc_rate_limit"`
+
+	signers map[string]types.Account // loaded once, so a signer read from stdin can be both fee payer and authority
+	client  *client.Client           // shared, so rate limits and endpoint health span all of a command's calls
+}
+
+// Flags are the command-line flags registered by Bind.
+type Flags struct {
+	defaults Config
+	path     *string
+	values   Config
+}
+
+// Bind registers -config, -cluster, -rpc-url, -rpc-fallback-urls, -rpc-rate-limit, -ws-url, -fee-payer,
+// -authority and -mint on fs.
+// defaults holds the command's own defaults, e.g. the cluster its example was written for;
+// empty fields fall back to devnet and DefaultKeypairPath.
+func Bind(fs *flag.FlagSet, defaults Config) *Flags {
+	f := &Flags{defaults: defaults}
+	f.path = fs.String("config", "", "YAML config file (default "+DefaultConfigPath+", or $"+EnvConfig+")")
+	fs.StringVar(&f.values.Cluster, "cluster", "", "cluster: localnet, devnet, testnet or mainnet")
+	fs.StringVar(&f.values.RPCURL, "rpc-url", "", "RPC endpoint, overriding the cluster's public one")
+	fs.Func("rpc-fallback-urls", "comma-separated RPC endpoints to fail over to", func(s string) error {
+		f.values.RPCFallbackURLs = splitList(s)
+		return nil
+	})
+	fs.Float64Var(&f.values.RPCRateLimit, "rpc-rate-limit", 0, "requests per second to each RPC endpoint (default 4 for the public endpoints, else unlimited)")
+	fs.StringVar(&f.values.WSURL, "ws-url", "", "WebSocket endpoint, derived from the RPC endpoint by default")
+	fs.StringVar(&f.values.FeePayer, "fee-payer", "", "fee payer keypair file, keystore name or signer URI (default "+DefaultKeypairPath+")")
+	fs.StringVar(&f.values.Authority, "authority", "", "owner and mint authority keypair file, keystore name or signer URI (default the fee payer)")
+	fs.StringVar(&f.values.Mint, "mint", "", "mint address")
+	return f
+}
+
+// Parse binds the config flags to the command line, parses it and loads the configuration.
+// Commands register their own flags before calling it.
+func Parse(defaults Config) (*Config, error) {
+	f := Bind(flag.CommandLine, defaults)
+	flag.Parse()
+	return f.Load()
+}
+
+// Load resolves the configuration once the flags have been parsed.
+func (f *Flags) Load() (*Config, error) {
+	cfg := f.defaults
+	if cfg.Cluster == "" {
+		cfg.Cluster = Devnet
+	}
+
+	// YAML file
+	path, explicit := *f.path, true
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	if path == "" {
+		path, explicit = DefaultConfigPath, false
+	}
+	if err := cfg.loadFile(pathutil.ExpandHome(path), explicit); err != nil {
+		return nil, err
+	}
+
+	// Environment
+	env := Config{
+		Cluster:         os.Getenv(EnvCluster),
+		RPCURL:          os.Getenv(EnvRPCURL),
+		WSURL:           os.Getenv(EnvWSURL),
+		FeePayer:        os.Getenv(EnvFeePayer),
+		Authority:       os.Getenv(EnvAuthority),
+		Mint:            os.Getenv(EnvMint),
+		RPCFallbackURLs: splitList(os.Getenv(EnvRPCFallbackURLs)),
+	}
+	if rate := os.Getenv(EnvRPCRateLimit); rate != "" {
+		var err error
+		if env.RPCRateLimit, err = strconv.ParseFloat(rate, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s %q", EnvRPCRateLimit, rate)
+		}
+	}
+	cfg.merge(env)
+
+	// Flags
+	cfg.merge(f.values)
+
+	if cfg.FeePayer == "" {
+		cfg.FeePayer = DefaultKeypairPath
+	}
+	if cfg.Authority == "" {
+		cfg.Authority = cfg.FeePayer
+	}
+	cfg.Cluster = strings.TrimSuffix(cfg.Cluster, "-beta")
+	if cfg.RPCURL == "" {
+		endpoint, err := Endpoint(cfg.Cluster)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RPCURL = endpoint
+	}
+	if cfg.RPCRateLimit < 0 {
+		return nil, fmt.Errorf("invalid RPC rate limit %v", cfg.RPCRateLimit)
+	}
+	// Built now so an invalid endpoint is reported here rather than on the first call
+	c, err := cfg.newClient()
+	if err != nil {
+		return nil, err
+	}
+	cfg.client = c
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	var file Config
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	// Keypair paths in the file are relative to it
+	for _, signer := range []*string{&file.FeePayer, &file.Authority} {
+		if isRelativePath(*signer) {
+			*signer = filepath.Join(filepath.Dir(path), *signer)
+		}
+	}
+	c.merge(file)
+	return nil
+}
+
+// isRelativePath reports whether a signer source is a relative file path,
+// rather than a URI such as base58: or the bare name of a keystore account.
+func isRelativePath(source string) bool {
+	return strings.ContainsAny(source, "/.") && !strings.Contains(source, ":") &&
+		!strings.HasPrefix(source, "~") && !filepath.IsAbs(source)
+}
+
+// merge overrides c with the non-empty fields of other.
+// Switching to another cluster drops the endpoints c had, which belong to the cluster it replaces.
+func (c *Config) merge(other Config) {
+	if other.Cluster != "" && strings.TrimSuffix(other.Cluster, "-beta") != strings.TrimSuffix(c.Cluster, "-beta") {
+		c.RPCURL = ""
+		c.WSURL = ""
+		c.RPCFallbackURLs = nil
+	}
+	for _, field := range []struct{ dst, src *string }{
+		{&c.Cluster, &other.Cluster},
+		{&c.RPCURL, &other.RPCURL},
+		{&c.WSURL, &other.WSURL},
+		{&c.FeePayer, &other.FeePayer},
+		{&c.Authority, &other.Authority},
+		{&c.Mint, &other.Mint},
+	} {
+		if *field.src != "" {
+			*field.dst = *field.src
+		}
+	}
+	if len(other.RPCFallbackURLs) > 0 {
+		c.RPCFallbackURLs = other.RPCFallbackURLs
+	}
+	if other.RPCRateLimit != 0 {
+		c.RPCRateLimit = other.RPCRateLimit
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Endpoint returns the public RPC endpoint of a cluster.
+func Endpoint(cluster string) (string, error) {
+	switch cluster {
+	case Localnet:
+		return rpc.LocalnetRPCEndpoint, nil
+	case Devnet:
+		return rpc.DevnetRPCEndpoint, nil
+	case Testnet:
+		return rpc.TestnetRPCEndpoint, nil
+	case Mainnet:
+		return rpc.MainnetRPCEndpoint, nil
+	default:
+		return "", fmt.Errorf("unknown cluster %q, expected localnet, devnet, testnet or mainnet", cluster)
+	}
+}
+
+// Client returns the client for the configured RPC endpoints. It retries rate limits and transient errors,
+// fails over to the fallback endpoints, and is shared by every caller so they share the rate limits too.
+// It returns an error if the endpoints are invalid, which Load has already reported for a loaded Config.
+func (c *Config) Client() (*client.Client, error) {
+	if c.client == nil {
+		cl, err := c.newClient()
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		c.client = cl
+	}
+	return c.client, nil
+}
+
+func (c *Config) newClient() (*client.Client, error) {
+	var opts []rpcclient.Option
+	if c.RPCRateLimit > 0 {
+		opts = append(opts, rpcclient.WithRateLimit(c.RPCRateLimit, max(int(c.RPCRateLimit), 1)))
+	}
+	return rpcclient.New(append([]string{c.RPCURL}, c.RPCFallbackURLs...), opts...)
+}
+
+// FeePayerAccount loads the fee payer keypair.
+func (c *Config) FeePayerAccount() (types.Account, error) {
+	return c.signer(c.FeePayer)
+}
+
+// AuthorityAccount loads the owner and mint authority keypair.
+func (c *Config) AuthorityAccount() (types.Account, error) {
+	return c.signer(c.Authority)
+}
+
+func (c *Config) signer(source string) (types.Account, error) {
+	if account, ok := c.signers[source]; ok {
+		return account, nil
+	}
+	account, err := keypair.LoadSigner(source)
+	if err != nil {
+		return types.Account{}, err
+	}
+	if c.signers == nil {
+		c.signers = make(map[string]types.Account)
+	}
+	c.signers[source] = account
+	return account, nil
+}
+
+// MintAddress returns the configured mint, or fallback when none is configured.
+func (c *Config) MintAddress(fallback string) string {
+	if c.Mint != "" {
+		return c.Mint
+	}
+	return fallback
+}
+
+// ExplorerTxURL links to a transaction on the Solana explorer for the configured cluster.
+func (c *Config) ExplorerTxURL(signature string) string {
+	link := "https://explorer.solana.com/tx/" + signature
+	switch {
+	case c.RPCURL != "" && !isPublicEndpoint(c.RPCURL):
+		return link + "?cluster=custom&customUrl=" + url.QueryEscape(c.RPCURL)
+	case c.Cluster == Mainnet:
+		return link
+	default:
+		return link + "?cluster=" + c.Cluster
+	}
+}
+
+func isPublicEndpoint(endpoint string) bool {
+	switch endpoint {
+	case rpc.DevnetRPCEndpoint, rpc.TestnetRPCEndpoint, rpc.MainnetRPCEndpoint:
+		return true
+	}
+	return false
+}
+
+// PublicKey parses a base58 address, unlike common.PublicKeyFromString reporting invalid input.
+func PublicKey(address string) (common.PublicKey, error) {
+	key, err := base58.Decode(address)
+	if err != nil || len(key) != common.PublicKeyLength {
+		return common.PublicKey{}, fmt.Errorf("invalid address %q", address)
+	}
+	return common.PublicKeyFromBytes(key), nil
+}
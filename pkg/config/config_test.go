@@ -0,0 +1,70 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	const fileRPCURL = "http://devnet-node.example:8899"
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		cluster string
+		rpcURL  string
+	}{
+		{"file", nil, nil, Devnet, fileRPCURL},
+		{"rpc-url flag", nil, []string{"-rpc-url", "http://flag.example"}, Devnet, "http://flag.example"},
+		{"cluster flag drops the file's endpoint", nil, []string{"-cluster", "mainnet"}, Mainnet, rpc.MainnetRPCEndpoint},
+		{"cluster flag with rpc-url flag", nil, []string{"-cluster", "mainnet", "-rpc-url", "http://flag.example"}, Mainnet, "http://flag.example"},
+		{"cluster env drops the file's endpoint", map[string]string{EnvCluster: "testnet"}, nil, Testnet, rpc.TestnetRPCEndpoint},
+		{"cluster flag drops the env endpoint", map[string]string{EnvRPCURL: "http://env.example"}, []string{"-cluster", "mainnet-beta"}, Mainnet, rpc.MainnetRPCEndpoint},
+		{"same cluster flag keeps the file's endpoint", nil, []string{"-cluster", "devnet"}, Devnet, fileRPCURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			data := "cluster: devnet\nrpc_url: " + fileRPCURL + "\nws_url: ws://devnet-node.example:8900\n"
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(EnvConfig, path)
+			for _, key := range []string{EnvCluster, EnvRPCURL, EnvWSURL, EnvRPCFallbackURLs, EnvRPCRateLimit} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			fs := flag.NewFlagSet(tt.name, flag.ContinueOnError)
+			f := Bind(fs, Config{})
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := f.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Cluster != tt.cluster || cfg.RPCURL != tt.rpcURL {
+				t.Errorf("got cluster %q at %q, want %q at %q", cfg.Cluster, cfg.RPCURL, tt.cluster, tt.rpcURL)
+			}
+			if cfg.Cluster != Devnet && cfg.WSURL != "" {
+				t.Errorf("WebSocket endpoint %q kept for another cluster", cfg.WSURL)
+			}
+			if _, err := cfg.Client(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestClientInvalidEndpoint(t *testing.T) {
+	for _, cfg := range []*Config{{}, {RPCURL: "not a url"}, {RPCURL: "https://api.devnet.solana.com", RPCFallbackURLs: []string{"ftp://example.com"}}} {
+		if _, err := cfg.Client(); err == nil {
+			t.Errorf("got no error for endpoint %q and fallbacks %q", cfg.RPCURL, cfg.RPCFallbackURLs)
+		}
+	}
+}
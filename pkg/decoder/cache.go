@@ -0,0 +1,267 @@
+package decoder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultTokenCacheTTL is how long token info is kept by the cache a Decoder creates for itself.
+const DefaultTokenCacheTTL = time.Hour
+
+// TokenCache is a concurrency-safe cache of token info keyed by mint address.
+// Concurrent lookups of the same mint share a single fetch, and failed fetches are not cached.
+// A TokenCache may be shared by several Decoders.
+type TokenCache struct {
+	ttl  time.Duration
+	path string
+
+	mu       sync.Mutex
+	entries  map[string]tokenCacheEntry
+	inflight map[string]*tokenFetch
+}
+
+type tokenCacheEntry struct {
+	Token   Token     `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// tokenFetch is a lookup in progress; waiters block on done.
+type tokenFetch struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// errFetchAborted is what waiters on a fetch get when it panicked instead of returning.
+var errFetchAborted = errors.New("decoder: token fetch aborted")
+
+// NewTokenCache returns an in-memory cache whose entries expire after ttl.
+// A ttl of zero or less keeps entries forever.
+func NewTokenCache(ttl time.Duration) *TokenCache {
+	return &TokenCache{
+		ttl:      ttl,
+		entries:  make(map[string]tokenCacheEntry),
+		inflight: make(map[string]*tokenFetch),
+	}
+}
+
+// LoadTokenCache returns a cache persisted at path, loading the unexpired entries saved there before.
+// A missing file is not an error. Call Save to write the cache back.
+func LoadTokenCache(path string, ttl time.Duration) (*TokenCache, error) {
+	cache := NewTokenCache(ttl)
+	cache.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+
+	var entries map[string]tokenCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache %s: %w", path, err)
+	}
+	now := time.Now()
+	for mint, entry := range entries {
+		if !entry.expired(now) {
+			cache.entries[mint] = entry
+		}
+	}
+	return cache, nil
+}
+
+// Save writes the unexpired entries to the path given to LoadTokenCache.
+// It does nothing for caches created with NewTokenCache.
+func (c *TokenCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	entries := make(map[string]tokenCacheEntry, len(c.entries))
+	for mint, entry := range c.entries {
+		if !entry.expired(now) {
+			entries[mint] = entry
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode token cache: %w", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated cache behind
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save token cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save token cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save token cache: %w", err)
+	}
+	return nil
+}
+
+// Get returns the cached token info for mint, calling fetch on a miss.
+func (c *TokenCache) Get(ctx context.Context, mint string, fetch func(ctx context.Context, mint string) (*Token, error)) (*Token, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[mint]; ok && !entry.expired(time.Now()) {
+		c.mu.Unlock()
+		token := entry.Token
+		return &token, nil
+	}
+	if f, ok := c.inflight[mint]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.token, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	f := &tokenFetch{done: make(chan struct{}), err: errFetchAborted}
+	c.inflight[mint] = f
+	c.mu.Unlock()
+
+	// Settled even if fetch panics, so waiters and later lookups are not blocked forever
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, mint)
+		if f.err == nil && f.token != nil {
+			c.entries[mint] = tokenCacheEntry{Token: *f.token, Expires: c.expiry()}
+		}
+		c.mu.Unlock()
+		close(f.done)
+	}()
+	f.token, f.err = fetch(ctx, mint)
+	return f.token, f.err
+}
+
+// GetMany returns the token info of every mint it can, calling fetch once for all the mints
+// that are neither cached nor being fetched by another caller. Mints already being fetched are waited for,
+// so concurrent callers share lookups just as with Get. Mints left out of fetch's result are missing from
+// the result and are not cached; if fetch fails, so are all the mints it was given.
+func (c *TokenCache) GetMany(ctx context.Context, mints []string, fetch func(ctx context.Context, mints []string) (map[string]*Token, error)) map[string]*Token {
+	var tokens = make(map[string]*Token, len(mints))
+	var claimed []string
+	var waiting = make(map[string]*tokenFetch)
+	var fetches = make(map[string]*tokenFetch)
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, mint := range mints {
+		if _, ok := tokens[mint]; ok {
+			continue
+		}
+		if _, ok := fetches[mint]; ok {
+			continue
+		}
+		if entry, ok := c.entries[mint]; ok && !entry.expired(now) {
+			token := entry.Token
+			tokens[mint] = &token
+			continue
+		}
+		if f, ok := c.inflight[mint]; ok {
+			waiting[mint] = f
+			continue
+		}
+		f := &tokenFetch{done: make(chan struct{})}
+		c.inflight[mint] = f
+		fetches[mint] = f
+		claimed = append(claimed, mint)
+	}
+	c.mu.Unlock()
+
+	if len(claimed) > 0 {
+		c.fetchClaimed(ctx, claimed, fetches, fetch)
+		for _, mint := range claimed {
+			if f := fetches[mint]; f.err == nil {
+				tokens[mint] = f.token
+			}
+		}
+	}
+
+	for mint, f := range waiting {
+		select {
+		case <-f.done:
+			if f.err == nil && f.token != nil {
+				tokens[mint] = f.token
+			}
+		case <-ctx.Done():
+			return tokens
+		}
+	}
+	return tokens
+}
+
+// fetchClaimed calls fetch for the mints GetMany claimed and settles their fetches, even if fetch panics.
+func (c *TokenCache) fetchClaimed(ctx context.Context, mints []string, fetches map[string]*tokenFetch, fetch func(ctx context.Context, mints []string) (map[string]*Token, error)) {
+	var fetched map[string]*Token
+	err := errFetchAborted
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, mint := range mints {
+			f := fetches[mint]
+			f.token, f.err = fetched[mint], err
+			if f.err == nil && f.token == nil {
+				f.err = fmt.Errorf("decoder: token %s not resolved", mint)
+			}
+			delete(c.inflight, mint)
+			if f.err == nil {
+				c.entries[mint] = tokenCacheEntry{Token: *f.token, Expires: c.expiry()}
+			}
+			close(f.done)
+		}
+	}()
+	fetched, err = fetch(ctx, mints)
+}
+
+// Lookup returns the cached token info for mint without fetching it.
+func (c *TokenCache) Lookup(mint string) (*Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[mint]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+	token := entry.Token
+	return &token, true
+}
+
+// Put stores token info, e.g. from a batch lookup, as if it had been fetched now.
+func (c *TokenCache) Put(token Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[token.Address] = tokenCacheEntry{Token: token, Expires: c.expiry()}
+}
+
+func (c *TokenCache) expiry() time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(c.ttl)
+}
+
+func (e tokenCacheEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
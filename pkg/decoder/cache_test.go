@@ -0,0 +1,57 @@
+package decoder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"solana-starter/pkg/decoder"
+)
+
+const testMint = "4zMMC9srt5Ri5X14GAgXhaHii3GnPAEERYPJgZJDncDU"
+
+// fetchPanics returns the value f panicked with, or nil.
+func fetchPanics(f func()) (recovered any) {
+	defer func() { recovered = recover() }()
+	f()
+	return nil
+}
+
+func TestTokenCachePanickingFetch(t *testing.T) {
+	want := &decoder.Token{Address: testMint, Decimals: 6, Symbol: "USDC"}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.Run("Get", func(t *testing.T) {
+		cache := decoder.NewTokenCache(0)
+		recovered := fetchPanics(func() {
+			cache.Get(ctx, testMint, func(context.Context, string) (*decoder.Token, error) { panic("fetch failed") })
+		})
+		if recovered != "fetch failed" {
+			t.Fatalf("recovered %v, want the fetch's panic", recovered)
+		}
+
+		// The panicked fetch no longer holds the mint, so the next lookup fetches it instead of waiting
+		token, err := cache.Get(ctx, testMint, func(context.Context, string) (*decoder.Token, error) { return want, nil })
+		if err != nil || token == nil || *token != *want {
+			t.Fatalf("got %+v, %v after a panicked fetch, want %+v", token, err, want)
+		}
+	})
+
+	t.Run("GetMany", func(t *testing.T) {
+		cache := decoder.NewTokenCache(0)
+		recovered := fetchPanics(func() {
+			cache.GetMany(ctx, []string{testMint}, func(context.Context, []string) (map[string]*decoder.Token, error) {
+				panic("fetch failed")
+			})
+		})
+		if recovered != "fetch failed" {
+			t.Fatalf("recovered %v, want the fetch's panic", recovered)
+		}
+
+		token, err := cache.Get(ctx, testMint, func(context.Context, string) (*decoder.Token, error) { return want, nil })
+		if err != nil || token == nil || *token != *want {
+			t.Fatalf("got %+v, %v after a panicked fetch, want %+v", token, err, want)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/compute_budget"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+const (
+	// lamportsPerSignature is the base fee charged for every signature of a transaction.
+	lamportsPerSignature = 5000
+	// microLamportsPerLamport converts compute unit prices, given in micro-lamports, to lamports.
+	microLamportsPerLamport = 1_000_000
+	// Without SetComputeUnitLimit a transaction may use this many units per instruction, up to maxComputeUnitLimit.
+	defaultInstructionComputeUnitLimit = 200_000
+	maxComputeUnitLimit                = 1_400_000
+)
+
+// ComputeUnitLimit is a Compute Budget SetComputeUnitLimit instruction.
+type ComputeUnitLimit struct {
+	Type      string `json:"type"`
+	Units     uint32 `json:"units"`
+	ProgramID string `json:"programID"`
+	Position
+}
+
+// ComputeUnitPrice is a Compute Budget SetComputeUnitPrice instruction.
+type ComputeUnitPrice struct {
+	Type          string `json:"type"`
+	MicroLamports uint64 `json:"microLamports"` // price of one compute unit
+	ProgramID     string `json:"programID"`
+	Position
+}
+
+// RequestHeapFrame is a Compute Budget RequestHeapFrame instruction.
+type RequestHeapFrame struct {
+	Type      string `json:"type"`
+	Bytes     uint32 `json:"bytes"`
+	ProgramID string `json:"programID"`
+	Position
+}
+
+func (e *ComputeUnitLimit) EventType() string { return e.Type }
+func (e *ComputeUnitPrice) EventType() string { return e.Type }
+func (e *RequestHeapFrame) EventType() string { return e.Type }
+
+func isComputeBudgetProgram(programID string) bool {
+	return programID == common.ComputeBudgetProgramID.String()
+}
+
+// tryDecodeComputeBudgetInstruction decodes the Compute Budget instructions that set a transaction's limits and price.
+// They are borsh encoded: a one-byte discriminator followed by a little endian integer.
+func tryDecodeComputeBudgetInstruction(instruction types.CompiledInstruction, indexAccountMap map[int]string) (Event, error) {
+	if len(instruction.Data) == 0 {
+		return nil, nil
+	}
+	programID := indexAccountMap[instruction.ProgramIDIndex]
+	data := instruction.Data[1:]
+
+	switch compute_budget.Instruction(instruction.Data[0]) {
+	case compute_budget.InstructionRequestHeapFrame:
+		if len(data) < 4 {
+			return nil, fmt.Errorf("%w: requestHeapFrame data length %d", ErrInvalidInstruction, len(instruction.Data))
+		}
+		return &RequestHeapFrame{Type: "requestHeapFrame", Bytes: binary.LittleEndian.Uint32(data), ProgramID: programID}, nil
+	case compute_budget.InstructionSetComputeUnitLimit:
+		if len(data) < 4 {
+			return nil, fmt.Errorf("%w: setComputeUnitLimit data length %d", ErrInvalidInstruction, len(instruction.Data))
+		}
+		return &ComputeUnitLimit{Type: "setComputeUnitLimit", Units: binary.LittleEndian.Uint32(data), ProgramID: programID}, nil
+	case compute_budget.InstructionSetComputeUnitPrice:
+		if len(data) < 8 {
+			return nil, fmt.Errorf("%w: setComputeUnitPrice data length %d", ErrInvalidInstruction, len(instruction.Data))
+		}
+		return &ComputeUnitPrice{Type: "setComputeUnitPrice", MicroLamports: binary.LittleEndian.Uint64(data), ProgramID: programID}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Fees breaks down what a transaction paid. Amounts are in lamports; the UI fields are in SOL.
+type Fees struct {
+	Signatures    int    `json:"signatures"`
+	BaseFee       uint64 `json:"baseFee"`     // lamportsPerSignature for every signature
+	PriorityFee   uint64 `json:"priorityFee"` // the rest of the fee, paid for the compute unit price
+	TotalFee      uint64 `json:"totalFee"`    // the fee recorded in the transaction meta
+	UiBaseFee     string `json:"uiBaseFee"`
+	UiPriorityFee string `json:"uiPriorityFee"`
+	UiTotalFee    string `json:"uiTotalFee"`
+
+	ComputeUnitPrice     uint64  `json:"computeUnitPrice"`     // micro-lamports per unit, zero without SetComputeUnitPrice
+	ComputeUnitLimit     uint32  `json:"computeUnitLimit"`     // requested, or the default for the transaction's instructions
+	ComputeUnitsConsumed *uint64 `json:"computeUnitsConsumed"` // nil if the node did not report it
+	// ConsumedPriorityFee is what the priority fee would have been had the limit matched the units consumed.
+	// The priority fee is charged on the limit, so the difference is what an oversized limit cost.
+	ConsumedPriorityFee   uint64 `json:"consumedPriorityFee"`
+	UiConsumedPriorityFee string `json:"uiConsumedPriorityFee"`
+}
+
+// ComputeFees works out the base and priority fees of a transaction from its Compute Budget instructions and meta.
+func ComputeFees(tx *client.Transaction) (*Fees, error) {
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if tx.Meta == nil {
+		return nil, ErrMissingMeta
+	}
+
+	fees := &Fees{
+		Signatures:           len(tx.Transaction.Signatures),
+		TotalFee:             tx.Meta.Fee,
+		ComputeUnitsConsumed: tx.Meta.ComputeUnitsConsumed,
+	}
+	fees.BaseFee = uint64(fees.Signatures) * lamportsPerSignature
+	if fees.TotalFee > fees.BaseFee {
+		fees.PriorityFee = fees.TotalFee - fees.BaseFee
+	}
+
+	accounts := tx.Transaction.Message.Accounts
+	var limitSet bool
+	var instructions uint32
+	for _, instruction := range tx.Transaction.Message.Instructions {
+		if instruction.ProgramIDIndex >= len(accounts) {
+			continue
+		}
+		programID := accounts[instruction.ProgramIDIndex].ToBase58()
+		if !isComputeBudgetProgram(programID) {
+			instructions++
+			continue
+		}
+		// Malformed instructions are skipped, as when decoding events
+		event, _ := tryDecodeComputeBudgetInstruction(instruction, map[int]string{instruction.ProgramIDIndex: programID})
+		switch event := event.(type) {
+		case *ComputeUnitLimit:
+			fees.ComputeUnitLimit = min(event.Units, maxComputeUnitLimit)
+			limitSet = true
+		case *ComputeUnitPrice:
+			fees.ComputeUnitPrice = event.MicroLamports
+		}
+	}
+	if !limitSet {
+		fees.ComputeUnitLimit = min(instructions*defaultInstructionComputeUnitLimit, maxComputeUnitLimit)
+	}
+	if fees.ComputeUnitsConsumed != nil {
+		fees.ConsumedPriorityFee = priorityFee(fees.ComputeUnitPrice, *fees.ComputeUnitsConsumed)
+	}
+
+	fees.UiBaseFee = uiAmount(fmt.Sprint(fees.BaseFee), nativeSOL.Decimals)
+	fees.UiPriorityFee = uiAmount(fmt.Sprint(fees.PriorityFee), nativeSOL.Decimals)
+	fees.UiTotalFee = uiAmount(fmt.Sprint(fees.TotalFee), nativeSOL.Decimals)
+	fees.UiConsumedPriorityFee = uiAmount(fmt.Sprint(fees.ConsumedPriorityFee), nativeSOL.Decimals)
+	return fees, nil
+}
+
+// priorityFee returns the lamports charged for units at a price in micro-lamports, rounded up as the runtime does.
+func priorityFee(microLamports, units uint64) uint64 {
+	// Split the product to avoid overflowing at extreme prices
+	whole := microLamports / microLamportsPerLamport * units
+	rest := microLamports % microLamportsPerLamport * units
+	return whole + (rest+microLamportsPerLamport-1)/microLamportsPerLamport
+}
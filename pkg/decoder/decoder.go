@@ -0,0 +1,379 @@
+// Package decoder extracts SPL Token, Token-2022 and native SOL transfers from Solana transactions.
+// Other token instructions such as mints, burns and approvals are available as events.
+//
+// A Decoder wraps a *client.Client, which it uses to fetch transactions and
+// to look up mint decimals and metadata for the tokens involved. Metadata is read
+// from Token-2022 metadata extensions or the Metaplex metadata account, and
+// optionally from a local token list.
+package decoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrTransactionNotFound is returned when the RPC node has no record of the signature.
+	ErrTransactionNotFound = errors.New("decoder: transaction not found")
+	// ErrMissingMeta is returned when a transaction carries no status meta, which the decoder needs for inner instructions.
+	ErrMissingMeta = errors.New("decoder: transaction has no meta")
+	// ErrInvalidInstruction is wrapped by errors about malformed instruction data or accounts.
+	// Such instructions are skipped while decoding a transaction.
+	ErrInvalidInstruction = errors.New("decoder: invalid instruction")
+)
+
+// Decoder decodes token transfers using c for transaction and token lookups.
+type Decoder struct {
+	c          *client.Client
+	tokens     *TokenCache
+	tokenList  *TokenList
+	programs   *ProgramRegistry
+	commitment rpc.Commitment
+	// failedTransfers makes DecodeTransaction decode failed transactions instead of returning ErrTransactionFailed
+	failedTransfers bool
+}
+
+// Option configures a Decoder.
+type Option func(*Decoder)
+
+// WithTokenCache makes the Decoder look up token info through cache, which may be shared with other Decoders.
+func WithTokenCache(cache *TokenCache) Option {
+	return func(d *Decoder) {
+		d.tokens = cache
+	}
+}
+
+// WithCommitment sets the commitment transactions are fetched at; the node default is finalized.
+// Use rpc.CommitmentConfirmed to decode transactions seen through confirmed subscriptions.
+func WithCommitment(commitment rpc.Commitment) Option {
+	return func(d *Decoder) {
+		d.commitment = commitment
+	}
+}
+
+// WithAttemptedTransfers makes DecodeSignature and DecodeTransaction decode the transfers a failed
+// transaction attempted, none of which took effect, instead of returning ErrTransactionFailed.
+func WithAttemptedTransfers() Option {
+	return func(d *Decoder) {
+		d.failedTransfers = true
+	}
+}
+
+// WithTokenList makes the Decoder take symbols and names from list for mints without on-chain metadata.
+func WithTokenList(list *TokenList) Option {
+	return func(d *Decoder) {
+		d.tokenList = list
+	}
+}
+
+// WithProgramRegistry sets the registry outer instruction programs are named from.
+func WithProgramRegistry(registry *ProgramRegistry) Option {
+	return func(d *Decoder) {
+		d.programs = registry
+	}
+}
+
+// New returns a Decoder backed by c. Unless WithTokenCache is given,
+// the Decoder caches token info in memory for DefaultTokenCacheTTL, and unless WithProgramRegistry
+// is given, it names programs from NewProgramRegistry.
+func New(c *client.Client, opts ...Option) *Decoder {
+	d := &Decoder{c: c}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.tokens == nil {
+		d.tokens = NewTokenCache(DefaultTokenCacheTTL)
+	}
+	if d.programs == nil {
+		d.programs = NewProgramRegistry()
+	}
+	return d
+}
+
+// DecodeSignature fetches the transaction identified by signature and decodes its transfers.
+// Like DecodeTransaction, it returns ErrTransactionFailed for a transaction that failed on chain.
+func (d *Decoder) DecodeSignature(ctx context.Context, signature string) ([]*Transfer, error) {
+	tx, err := d.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeTransaction(ctx, tx)
+}
+
+// DecodeTransaction decodes the transfers of an already-fetched transaction.
+// Outer instructions are reported first, followed by inner instructions in the order they were executed.
+// A failed transaction moved nothing, so ErrTransactionFailed is returned for it unless the Decoder is built
+// WithAttemptedTransfers; DecodeTransactionEnvelope decodes it too and flags it by its status.
+func (d *Decoder) DecodeTransaction(ctx context.Context, tx *client.Transaction) ([]*Transfer, error) {
+	if !d.failedTransfers && tx != nil && tx.Meta != nil && tx.Meta.Err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTransactionFailed, tx.Meta.Err)
+	}
+	events, err := d.DecodeTransactionEvents(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return transfersOf(events), nil
+}
+
+// DecodeSignatureEvents fetches the transaction identified by signature and decodes all of its supported instructions.
+func (d *Decoder) DecodeSignatureEvents(ctx context.Context, signature string) ([]Event, error) {
+	tx, err := d.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeTransactionEvents(ctx, tx)
+}
+
+// GetTransaction fetches the transaction identified by signature at the Decoder's commitment,
+// returning ErrTransactionNotFound if the node has no record of it.
+func (d *Decoder) GetTransaction(ctx context.Context, signature string) (*client.Transaction, error) {
+	// Query transaction details. The client asks for maxSupportedTransactionVersion 0,
+	// without which the node refuses to return v0 transactions.
+	tx, err := d.c.GetTransactionWithConfig(ctx, signature, client.GetTransactionConfig{
+		Commitment: d.commitment,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching transaction details: %w", err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, signature)
+	}
+	return tx, nil
+}
+
+// DecodeTransactionEvents decodes every supported token and system instruction of an already-fetched transaction.
+// Events are ordered like DecodeTransaction orders transfers.
+func (d *Decoder) DecodeTransactionEvents(ctx context.Context, tx *client.Transaction) ([]Event, error) {
+	decoded, err := d.decodeEvents(tx)
+	if err != nil {
+		return nil, err
+	}
+	d.resolveOwners(ctx, []*decodedTx{decoded})
+	deriveMints(decoded)
+	d.populateTokens(ctx, decoded.events)
+	return decoded.events, nil
+}
+
+// DecodeTransactionsEvents decodes a batch of already-fetched transactions, e.g. the transactions of a block.
+// Lookups for all of them are made together, so the batch costs at most three rounds of chunked
+// GetMultipleAccounts calls however many transactions and mints it holds: one for the owners of token accounts
+// missing from the token balances, and two for the mints and their metadata.
+// The result holds the events of txs[i] at index i.
+func (d *Decoder) DecodeTransactionsEvents(ctx context.Context, txs []*client.Transaction) ([][]Event, error) {
+	var decoded = make([]*decodedTx, 0, len(txs))
+	for i, tx := range txs {
+		decodedTx, err := d.decodeEvents(tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		decoded = append(decoded, decodedTx)
+	}
+
+	// Get the wallets owning both sides of every transfer, fetching accounts without balance info
+	d.resolveOwners(ctx, decoded)
+
+	var eventsByTx = make([][]Event, 0, len(decoded))
+	var allEvents []Event
+	for _, tx := range decoded {
+		deriveMints(tx)
+		eventsByTx = append(eventsByTx, tx.events)
+		allEvents = append(allEvents, tx.events...)
+	}
+	d.populateTokens(ctx, allEvents)
+	return eventsByTx, nil
+}
+
+// decodedTx is the events of a transaction with what its meta tells about the token accounts it touched.
+type decodedTx struct {
+	events        []Event
+	tokenAccounts map[string]tokenAccount
+}
+
+// decodeEvents decodes the instructions of tx and resolves their accounts from the transaction alone,
+// leaving owners to resolveOwners and token info to populateTokens.
+func (d *Decoder) decodeEvents(tx *client.Transaction) (*decodedTx, error) {
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if tx.Meta == nil {
+		return nil, ErrMissingMeta
+	}
+
+	// Cache the account index mapping, including accounts loaded from address lookup tables
+	var indexAccountMap = make(map[int]string)
+	for i, account := range accountKeys(tx) {
+		indexAccountMap[i] = account
+	}
+
+	// Cache the instruction programIDIndex mapping
+	var instructionIndexProgramIDMap = make(map[int]int)
+	for i, instruction := range tx.Transaction.Message.Instructions {
+		instructionIndexProgramIDMap[i] = instruction.ProgramIDIndex
+	}
+
+	// Cache the mint and owner of every token account with a pre or post balance
+	tokenAccounts := tokenAccountsFromBalances(tx.Meta, indexAccountMap)
+
+	var allEvents []Event
+
+	// Process outer instructions
+	for i, instruction := range tx.Transaction.Message.Instructions {
+		programID := indexAccountMap[instruction.ProgramIDIndex]
+		event, _ := decodeInstruction(programID, instruction, indexAccountMap)
+		if event != nil {
+			*event.position() = Position{
+				IsInnerInstruction:          false,
+				OuterInstructionIndex:       i,
+				OuterInstructionProgramID:   programID,
+				OuterInstructionProgramName: d.programs.Name(programID),
+			}
+			allEvents = append(allEvents, event)
+		}
+	}
+
+	// Process inner instructions
+	for _, innerInstructions := range tx.Meta.InnerInstructions {
+		outerProgramIDIndex := instructionIndexProgramIDMap[int(innerInstructions.Index)]
+		outerProgramID := indexAccountMap[outerProgramIDIndex]
+		for _, instruction := range innerInstructions.Instructions {
+			programID := indexAccountMap[instruction.ProgramIDIndex]
+			event, _ := decodeInstruction(programID, instruction, indexAccountMap)
+			if event != nil {
+				*event.position() = Position{
+					IsInnerInstruction:          true,
+					OuterInstructionIndex:       int(innerInstructions.Index),
+					OuterInstructionProgramID:   outerProgramID,
+					OuterInstructionProgramName: d.programs.Name(outerProgramID),
+				}
+				allEvents = append(allEvents, event)
+			}
+		}
+	}
+
+	// Resolve the mint of events that only reference token accounts
+	for _, event := range allEvents {
+		switch event := event.(type) {
+		case *Transfer:
+			if event.TokenAddress == "" {
+				event.TokenAddress = resolveMint(tokenAccounts, event.Source, event.Destination)
+			}
+		case *Approve:
+			if event.TokenAddress == "" {
+				event.TokenAddress = resolveMint(tokenAccounts, event.Source)
+			}
+		}
+	}
+
+	return &decodedTx{events: allEvents, tokenAccounts: tokenAccounts}, nil
+}
+
+// deriveMints is the fallback for transfers whose mint neither the token balances nor the owner lookup told.
+func deriveMints(tx *decodedTx) {
+	allEvents, tokenAccounts := tx.events, tx.tokenAccounts
+	var unresolved []*Transfer
+	for _, transfer := range transfersOf(allEvents) {
+		if transfer.TokenAddress == "" {
+			unresolved = append(unresolved, transfer)
+		}
+	}
+
+	// Fall back to deriving the mint for transfers whose token accounts could not be resolved
+	if len(unresolved) > 0 {
+		// Get all the authorities and mints seen in this transaction
+		var authorities = make(map[string]struct{})
+		for _, transfer := range transfersOf(allEvents) {
+			if transfer.TokenAddress == NativeSOLMint {
+				continue
+			}
+			authorities[transfer.Authority] = struct{}{}
+		}
+		var mintAddresses = make(map[string]struct{})
+		for _, info := range tokenAccounts {
+			mintAddresses[info.Mint] = struct{}{}
+		}
+
+		for _, transfer := range unresolved {
+			derivedMint := tryDeriveMintAddress(transfer.Source, transfer.Destination, transfer.ProgramID, authorities, mintAddresses)
+			if derivedMint != "" {
+				transfer.TokenAddress = derivedMint
+			}
+		}
+	}
+}
+
+// populateTokens gets and populates token info for all events(which mint is not empty).
+// Mints missing from the cache are fetched in one batch through the cache, so mints another Decoder
+// sharing it is already fetching are waited for rather than fetched twice.
+func (d *Decoder) populateTokens(ctx context.Context, events []Event) {
+	var mints []string
+	for _, event := range events {
+		if tokenEvent, ok := event.(tokenEvent); ok {
+			if mint := tokenEvent.tokenAddress(); mint != "" && mint != NativeSOLMint {
+				mints = append(mints, mint)
+			}
+		}
+	}
+	tokens := d.tokens.GetMany(ctx, mints, d.fetchTokens)
+	tokens[NativeSOLMint] = &nativeSOL
+
+	for _, event := range events {
+		tokenEvent, ok := event.(tokenEvent)
+		if !ok {
+			continue
+		}
+		if token := tokens[tokenEvent.tokenAddress()]; token != nil {
+			tokenEvent.setToken(token)
+		}
+	}
+}
+
+// accountKeys returns the account keys instructions index into: the static message keys,
+// then the addresses a v0 transaction loaded from lookup tables, writable before readonly.
+func accountKeys(tx *client.Transaction) []string {
+	loaded := tx.Meta.LoadedAddresses
+	keys := make([]string, 0, len(tx.Transaction.Message.Accounts)+len(loaded.Writable)+len(loaded.Readonly))
+	for _, account := range tx.Transaction.Message.Accounts {
+		keys = append(keys, account.ToBase58())
+	}
+	keys = append(keys, loaded.Writable...)
+	keys = append(keys, loaded.Readonly...)
+	return keys
+}
+
+// decodeInstruction decodes instruction with the decoder matching programID.
+// It returns nil for programs and instructions that are not supported.
+func decodeInstruction(programID string, instruction types.CompiledInstruction, indexAccountMap map[int]string) (Event, error) {
+	switch {
+	case isTokenProgram(programID):
+		return tryDecodeTokenInstruction(instruction, indexAccountMap)
+	case isSystemProgram(programID):
+		transfer, err := tryDecodeSystemTransfer(instruction, indexAccountMap)
+		if transfer == nil {
+			return nil, err
+		}
+		return transfer, err
+	case isComputeBudgetProgram(programID):
+		return tryDecodeComputeBudgetInstruction(instruction, indexAccountMap)
+	default:
+		return nil, nil
+	}
+}
+
+// uiAmount scales a raw integer amount down by decimals.
+func uiAmount(amount string, decimals uint8) string {
+	if decimals <= 0 {
+		return amount
+	}
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return ""
+	}
+	divisor := decimal.New(1, int32(decimals))
+	return value.Div(divisor).String()
+}
@@ -0,0 +1,119 @@
+package decoder_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/fixture"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata instead of comparing")
+
+// goldenTests are the transactions saved in testdata; testdata/README.md says where each comes from.
+var goldenTests = []struct {
+	name      string
+	signature string
+}{
+	{"associated token account creation", "4Dj8Xbs7L6z7pbNp5eGZXLmYZLwePPRVTfunjx2EWDc4nwtVYRq4YqduiFKXR23cGqmbF6LHoubGnKa7gCozstGF"},
+	{"v0 transferChecked over lookup tables", "4fSTSDTTuYa1XXAFxFenuY3SoZWUwCzpMq7kUiya1zW6uqqh6C76GFqTQ3wvegEbZhbPJyr33iDAbieQVWCtVXmf"},
+	{"SOL transfer", "3HEwBzt8CuMGrTxC9s3qDMLCvpXf2Bmka6fA7nvUA1nAEVg1FeU4GNfxVT2U4TAuxQQPQ5igkjjMmNHXarQJvdVc"},
+	{"Token-2022 transferCheckedWithFee", "5uF2taJwwa6aru3XGx93KYbRFCqVG8JTCoL447CqGbS6wHmbXoF9tTttACdkqStdUhzrHqyLPzJQTmadZeuHmnA8"},
+}
+
+// TestDecodeGolden decodes every fixture offline and compares the events with <signature>.golden.json.
+// Run with -update to rewrite the golden files after an intended change.
+func TestDecodeGolden(t *testing.T) {
+	const dir = "testdata"
+	d, err := fixture.NewDecoder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A fixture missing from the table would never be checked
+	signatures, err := fixture.Signatures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signature := range signatures {
+		if !slices.ContainsFunc(goldenTests, func(tt struct{ name, signature string }) bool { return tt.signature == signature }) {
+			t.Errorf("fixture %s has no golden test", signature)
+		}
+	}
+
+	for _, tt := range goldenTests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := d.DecodeSignatureEvents(context.Background(), tt.signature)
+			if err != nil {
+				t.Fatalf("failed to decode %s: %v", tt.signature, err)
+			}
+			got, err := json.MarshalIndent(events, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join(dir, tt.signature+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("decoded events differ from %s, got:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// TestDecodeFailedTransaction checks that a failed transaction is not reported as completed transfers
+// unless the attempted transfers are asked for.
+func TestDecodeFailedTransaction(t *testing.T) {
+	const dir = "testdata"
+	signature := goldenTests[1].signature
+	tx, err := fixture.LoadTransaction(context.Background(), filepath.Join(dir, signature+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Meta.Err = map[string]any{"InstructionError": []any{0, "InsufficientFunds"}}
+
+	d, err := fixture.NewDecoder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transfers, err := d.DecodeTransaction(context.Background(), tx); !errors.Is(err, decoder.ErrTransactionFailed) {
+		t.Errorf("got %d transfers and error %v, want %v", len(transfers), err, decoder.ErrTransactionFailed)
+	}
+
+	d, err = fixture.NewDecoder(dir, decoder.WithAttemptedTransfers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfers, err := d.DecodeTransaction(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) == 0 {
+		t.Error("got no attempted transfers")
+	}
+
+	envelope, err := d.DecodeTransactionEnvelope(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !envelope.Failed() || len(envelope.Transfers()) != 0 {
+		t.Errorf("got envelope status %q with %d transfers, want %q with none", envelope.Status, len(envelope.Transfers()), decoder.StatusFailed)
+	}
+}
@@ -0,0 +1,96 @@
+package decoder
+
+import (
+	"context"
+	"errors"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/mr-tron/base58"
+)
+
+// ErrTransactionFailed is returned by DecodeSignature and DecodeTransaction for transactions that failed
+// on chain, whose instructions moved nothing, unless the Decoder is built WithAttemptedTransfers.
+var ErrTransactionFailed = errors.New("decoder: transaction failed")
+
+// Transaction statuses.
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Envelope is a decoded transaction with its transaction-level context.
+type Envelope struct {
+	Signature            string  `json:"signature"`
+	Slot                 uint64  `json:"slot"`
+	BlockTime            *int64  `json:"blockTime"` // unix seconds, nil if the node does not know it
+	FeePayer             string  `json:"feePayer"`
+	Fee                  uint64  `json:"fee"` // lamports
+	Status               string  `json:"status"`
+	Err                  any     `json:"err,omitempty"` // the on-chain error of a failed transaction
+	ComputeUnitsConsumed *uint64 `json:"computeUnitsConsumed,omitempty"`
+	Fees                 *Fees   `json:"fees"` // base and priority fee breakdown
+	// Events are the decoded instructions. For a failed transaction they are what it attempted; none took effect.
+	Events []Event `json:"events"`
+}
+
+// Failed reports whether the transaction failed on chain.
+func (e *Envelope) Failed() bool {
+	return e.Status == StatusFailed
+}
+
+// Transfers returns the transfers the transaction made, which is none if it failed.
+func (e *Envelope) Transfers() []*Transfer {
+	if e.Failed() {
+		return nil
+	}
+	return transfersOf(e.Events)
+}
+
+// DecodeSignatureEnvelope fetches the transaction identified by signature and decodes it with its context.
+func (d *Decoder) DecodeSignatureEnvelope(ctx context.Context, signature string) (*Envelope, error) {
+	tx, err := d.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeTransactionEnvelope(ctx, tx)
+}
+
+// DecodeTransactionEnvelope decodes an already-fetched transaction with its context.
+// Failed transactions are decoded too, and flagged by the envelope's status.
+func (d *Decoder) DecodeTransactionEnvelope(ctx context.Context, tx *client.Transaction) (*Envelope, error) {
+	events, err := d.DecodeTransactionEvents(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := ComputeFees(tx)
+	if err != nil {
+		return nil, err
+	}
+	envelope := newEnvelope(tx)
+	envelope.Fees = fees
+	envelope.Events = events
+	return envelope, nil
+}
+
+// newEnvelope fills in the transaction-level fields of tx, which must have meta.
+func newEnvelope(tx *client.Transaction) *Envelope {
+	envelope := &Envelope{
+		Slot:                 tx.Slot,
+		BlockTime:            tx.BlockTime,
+		Fee:                  tx.Meta.Fee,
+		Status:               StatusSuccess,
+		ComputeUnitsConsumed: tx.Meta.ComputeUnitsConsumed,
+	}
+	if len(tx.Transaction.Signatures) > 0 {
+		envelope.Signature = base58.Encode(tx.Transaction.Signatures[0])
+	}
+	// The fee payer is always the first account of the message
+	if len(tx.Transaction.Message.Accounts) > 0 {
+		envelope.FeePayer = tx.Transaction.Message.Accounts[0].ToBase58()
+	}
+	if tx.Meta.Err != nil {
+		envelope.Status = StatusFailed
+		envelope.Err = tx.Meta.Err
+	}
+	return envelope
+}
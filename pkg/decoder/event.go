@@ -0,0 +1,46 @@
+package decoder
+
+// Event is a decoded instruction. The concrete types are *Transfer, *MintTo,
+// *Burn, *Approve, *Revoke, *CloseAccount, *SetAuthority, *FreezeAccount,
+// *InitializeAccount, *ComputeUnitLimit, *ComputeUnitPrice and *RequestHeapFrame;
+// use a type switch to tell them apart.
+type Event interface {
+	// EventType returns the instruction name, e.g. "transferChecked" or "burn".
+	EventType() string
+	position() *Position
+}
+
+// Position locates the instruction an event was decoded from.
+// For inner instructions the outer index and program are those of the top-level instruction that invoked it.
+type Position struct {
+	IsInnerInstruction          bool   `json:"isInnerInstruction"`
+	OuterInstructionIndex       int    `json:"outerInstructionIndex"`
+	OuterInstructionProgramID   string `json:"outerInstructionProgramID"`
+	OuterInstructionProgramName string `json:"outerInstructionProgramName,omitempty"` // from the Decoder's ProgramRegistry
+}
+
+func (p *Position) position() *Position { return p }
+
+// PositionOf returns where e sits in its transaction.
+func PositionOf(e Event) Position {
+	return *e.position()
+}
+
+// tokenEvent is implemented by events that move or authorise an amount of a mint,
+// so they can be enriched with decimals and symbol once the mint is known.
+type tokenEvent interface {
+	Event
+	tokenAddress() string
+	setToken(token *Token)
+}
+
+// transfersOf returns the transfers among events, preserving order.
+func transfersOf(events []Event) []*Transfer {
+	var transfers []*Transfer
+	for _, event := range events {
+		if transfer, ok := event.(*Transfer); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers
+}
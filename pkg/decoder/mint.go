@@ -0,0 +1,82 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+// tokenAccount is what the transaction meta tells us about a token account it touched.
+type tokenAccount struct {
+	Mint      string
+	Owner     string
+	ProgramID string
+	Decimals  uint8
+}
+
+// tokenAccountsFromBalances maps every token account listed in the pre or post token balances
+// to its mint and owner. Post balances win, so accounts created by the transaction are included.
+func tokenAccountsFromBalances(meta *client.TransactionMeta, indexAccountMap map[int]string) map[string]tokenAccount {
+	var tokenAccounts = make(map[string]tokenAccount)
+	for _, balances := range [][]rpc.TransactionMetaTokenBalance{meta.PreTokenBalances, meta.PostTokenBalances} {
+		for _, balance := range balances {
+			address, ok := indexAccountMap[int(balance.AccountIndex)]
+			if !ok {
+				continue
+			}
+			tokenAccounts[address] = tokenAccount{
+				Mint:      balance.Mint,
+				Owner:     balance.Owner,
+				ProgramID: balance.ProgramId,
+				Decimals:  balance.UITokenAmount.Decimals,
+			}
+		}
+	}
+	return tokenAccounts
+}
+
+// resolveMint returns the mint of the first of accounts found in tokenAccounts.
+func resolveMint(tokenAccounts map[string]tokenAccount, accounts ...string) string {
+	for _, account := range accounts {
+		if info, ok := tokenAccounts[account]; ok && info.Mint != "" {
+			return info.Mint
+		}
+	}
+	return ""
+}
+
+// tryDeriveMintAddress is the fallback for token accounts missing from the token balances.
+// It assume source or destination address is ATA address, compare it to derivedATA, if it's match, then return mint address
+func tryDeriveMintAddress(source, destination, tokenProgramID string, authorities map[string]struct{}, mintAddresses map[string]struct{}) string {
+	for authority := range authorities {
+		for mintAddress := range mintAddresses {
+			derivedATA, _ := deriveAssociatedTokenAddress(
+				common.PublicKeyFromString(authority),
+				common.PublicKeyFromString(mintAddress),
+				common.PublicKeyFromString(tokenProgramID),
+			)
+			if derivedATA.ToBase58() == source || derivedATA.ToBase58() == destination {
+				return mintAddress
+			}
+		}
+	}
+	return ""
+}
+
+// deriveAssociatedTokenAddress derives the associated token address for a given owner and mint under tokenProgramID
+func deriveAssociatedTokenAddress(owner, mint, tokenProgramID common.PublicKey) (common.PublicKey, error) {
+	seeds := [][]byte{
+		owner.Bytes(),
+		tokenProgramID.Bytes(),
+		mint.Bytes(),
+	}
+
+	programDerivedAddress, _, err := common.FindProgramAddress(seeds, common.SPLAssociatedTokenAccountProgramID)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("failed to find program address: %w", err)
+	}
+
+	return programDerivedAddress, nil
+}
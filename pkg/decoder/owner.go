@@ -0,0 +1,101 @@
+package decoder
+
+import (
+	"context"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+)
+
+// resolveOwners fills in SourceOwner and DestinationOwner of the transfers of every transaction, and the mint of
+// transfers whose token accounts had to be fetched.
+// Owners come from the pre and post token balances of each transaction whenever they list the account,
+// as those record the owner at transaction time. Only the remaining token accounts are fetched, for all the
+// transactions together in chunked GetMultipleAccounts calls, and those report the owner at query time:
+// an account whose owner changed since, through SetAuthority, shows its current owner.
+// Accounts that no longer exist, e.g. closed later in the same transaction, or that are not token accounts
+// stay without owner.
+func (d *Decoder) resolveOwners(ctx context.Context, txs []*decodedTx) {
+	var missing []string
+	var seen = make(map[string]struct{})
+	for _, tx := range txs {
+		for _, transfer := range transfersOf(tx.events) {
+			if transfer.TokenAddress == NativeSOLMint {
+				continue
+			}
+			for _, account := range []string{transfer.Source, transfer.Destination} {
+				if _, ok := seen[account]; ok || account == "" {
+					continue
+				}
+				if info, ok := tx.tokenAccounts[account]; !ok || info.Owner == "" {
+					seen[account] = struct{}{}
+					missing = append(missing, account)
+				}
+			}
+		}
+	}
+
+	var fetched = make(map[string]tokenAccount)
+	if len(missing) > 0 {
+		accountInfos, err := d.getMultipleAccounts(ctx, missing)
+		if err == nil {
+			for i, accountInfo := range accountInfos {
+				data := accountInfo.Data
+				if (accountInfo.Owner != common.TokenProgramID && accountInfo.Owner != common.Token2022ProgramID) ||
+					len(data) < token.TokenAccountSize {
+					continue
+				}
+				// Token-2022 accounts append extensions after the base layout
+				tokenAccountInfo, err := token.TokenAccountFromData(data[:token.TokenAccountSize])
+				if err != nil {
+					continue
+				}
+				fetched[missing[i]] = tokenAccount{
+					Mint:  tokenAccountInfo.Mint.ToBase58(),
+					Owner: tokenAccountInfo.Owner.ToBase58(),
+				}
+			}
+		}
+	}
+
+	for _, tx := range txs {
+		for account, fetchedInfo := range fetched {
+			info, ok := tx.tokenAccounts[account]
+			if ok && info.Owner != "" {
+				continue
+			}
+			if !ok && !touches(tx.events, account) {
+				continue
+			}
+			info.Owner = fetchedInfo.Owner
+			if info.Mint == "" {
+				info.Mint = fetchedInfo.Mint
+			}
+			tx.tokenAccounts[account] = info
+		}
+
+		for _, transfer := range transfersOf(tx.events) {
+			if transfer.TokenAddress == NativeSOLMint {
+				// lamports move between wallets directly
+				transfer.SourceOwner = transfer.Source
+				transfer.DestinationOwner = transfer.Destination
+				continue
+			}
+			transfer.SourceOwner = tx.tokenAccounts[transfer.Source].Owner
+			transfer.DestinationOwner = tx.tokenAccounts[transfer.Destination].Owner
+			if transfer.TokenAddress == "" {
+				transfer.TokenAddress = resolveMint(tx.tokenAccounts, transfer.Source, transfer.Destination)
+			}
+		}
+	}
+}
+
+// touches reports whether a transfer among events moves tokens from or to account.
+func touches(events []Event, account string) bool {
+	for _, transfer := range transfersOf(events) {
+		if transfer.Source == account || transfer.Destination == account {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/blocto/solana-go-sdk/common"
+)
+
+// Program categories used by the built-in registry. Registries loaded from a file may use others.
+const (
+	CategoryAMM        = "amm"
+	CategoryAggregator = "aggregator"
+	CategoryLaunchpad  = "launchpad"
+	CategoryToken      = "token"
+	CategorySystem     = "system"
+)
+
+// Program names a well-known on-chain program.
+type Program struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// knownPrograms are the programs every ProgramRegistry starts with.
+var knownPrograms = []Program{
+	{ID: "675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8", Name: "Raydium AMM v4", Category: CategoryAMM},
+	{ID: "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc", Name: "Orca Whirlpool", Category: CategoryAMM},
+	{ID: "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4", Name: "Jupiter v6", Category: CategoryAggregator},
+	{ID: "LBUZKhRxPF3XUpBCjp4YzTKgLccjZhTSDM9YuVaPwxo", Name: "Meteora DLMM", Category: CategoryAMM},
+	{ID: "Eo7WjKq67rjJQSZxS6z3YkapzY3eMj6Xy8X5EQVn5UaB", Name: "Meteora Pools", Category: CategoryAMM},
+	{ID: "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P", Name: "Pump.fun", Category: CategoryLaunchpad},
+	{ID: common.TokenProgramID.ToBase58(), Name: "Token", Category: CategoryToken},
+	{ID: common.Token2022ProgramID.ToBase58(), Name: "Token-2022", Category: CategoryToken},
+	{ID: common.SPLAssociatedTokenAccountProgramID.ToBase58(), Name: "Associated Token Account", Category: CategoryToken},
+	{ID: common.SystemProgramID.ToBase58(), Name: "System", Category: CategorySystem},
+	{ID: common.ComputeBudgetProgramID.ToBase58(), Name: "Compute Budget", Category: CategorySystem},
+}
+
+// ProgramRegistry maps program IDs to names and categories.
+type ProgramRegistry struct {
+	programs map[string]Program
+}
+
+// NewProgramRegistry returns a registry of the well-known programs: the major AMMs, Jupiter, Pump.fun,
+// the token programs and the native programs.
+func NewProgramRegistry() *ProgramRegistry {
+	r := &ProgramRegistry{programs: make(map[string]Program, len(knownPrograms))}
+	for _, program := range knownPrograms {
+		r.Register(program)
+	}
+	return r
+}
+
+// LoadProgramRegistry returns the well-known programs extended with those in the JSON file at path,
+// an array of {"id", "name", "category"} objects. Entries in the file replace built-in ones with the same ID.
+func LoadProgramRegistry(path string) (*ProgramRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program registry: %w", err)
+	}
+	var programs []Program
+	if err := json.Unmarshal(data, &programs); err != nil {
+		return nil, fmt.Errorf("failed to parse program registry %s: %w", path, err)
+	}
+
+	r := NewProgramRegistry()
+	for _, program := range programs {
+		if program.ID == "" {
+			continue
+		}
+		r.Register(program)
+	}
+	return r, nil
+}
+
+// Register adds or replaces a program.
+func (r *ProgramRegistry) Register(program Program) {
+	r.programs[program.ID] = program
+}
+
+// Lookup returns the program with the given ID.
+func (r *ProgramRegistry) Lookup(id string) (Program, bool) {
+	if r == nil {
+		return Program{}, false
+	}
+	program, ok := r.programs[id]
+	return program, ok
+}
+
+// Name returns the name of the program with the given ID, or "" if it is not registered.
+func (r *ProgramRegistry) Name(id string) string {
+	program, _ := r.Lookup(id)
+	return program.Name
+}
@@ -0,0 +1,147 @@
+package decoder
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/blocto/solana-go-sdk/client"
+)
+
+// Discrepancy is a token account whose balance change disagrees with the decoded events.
+type Discrepancy struct {
+	Account      string `json:"account"`
+	TokenAddress string `json:"tokenAddress"`
+	Owner        string `json:"owner"`
+	BalanceDelta string `json:"balanceDelta"` // post minus pre token balance
+	DecodedDelta string `json:"decodedDelta"` // net of decoded transfers, mints and burns
+	Difference   string `json:"difference"`   // BalanceDelta minus DecodedDelta
+}
+
+// Reconcile compares the net change of every token account, taken from the pre and post token balances of tx,
+// with the net change implied by events. Any disagreement means an instruction or program the decoder does not cover
+// moved tokens. A common example is wrapped SOL funded with a system transfer followed by SyncNative.
+func Reconcile(tx *client.Transaction, events []Event) ([]Discrepancy, error) {
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+	if tx.Meta == nil {
+		return nil, ErrMissingMeta
+	}
+
+	var indexAccountMap = make(map[int]string)
+	for i, account := range accountKeys(tx) {
+		indexAccountMap[i] = account
+	}
+	tokenAccounts := tokenAccountsFromBalances(tx.Meta, indexAccountMap)
+
+	// Net change per account according to the token balances
+	var balanceDelta = make(map[string]*big.Int)
+	add := func(deltas map[string]*big.Int, account, amount string, sign int) {
+		value, ok := new(big.Int).SetString(amount, 10)
+		if !ok || account == "" {
+			return
+		}
+		if deltas[account] == nil {
+			deltas[account] = new(big.Int)
+		}
+		if sign < 0 {
+			value.Neg(value)
+		}
+		deltas[account].Add(deltas[account], value)
+	}
+	for _, balance := range tx.Meta.PreTokenBalances {
+		add(balanceDelta, indexAccountMap[int(balance.AccountIndex)], balance.UITokenAmount.Amount, -1)
+	}
+	for _, balance := range tx.Meta.PostTokenBalances {
+		add(balanceDelta, indexAccountMap[int(balance.AccountIndex)], balance.UITokenAmount.Amount, 1)
+	}
+
+	// Net change per account according to the decoded events
+	var decodedDelta = make(map[string]*big.Int)
+	var closed = make(map[string]struct{})
+	for _, event := range events {
+		switch event := event.(type) {
+		case *Transfer:
+			if event.TokenAddress == NativeSOLMint {
+				continue
+			}
+			add(decodedDelta, event.Source, event.Amount, -1)
+			add(decodedDelta, event.Destination, event.Amount, 1)
+			if event.Fee != "" {
+				// the fee is withheld in the destination account and not part of its balance
+				add(decodedDelta, event.Destination, event.Fee, -1)
+			}
+		case *MintTo:
+			add(decodedDelta, event.Account, event.Amount, 1)
+		case *Burn:
+			add(decodedDelta, event.Account, event.Amount, -1)
+		case *CloseAccount:
+			closed[event.Account] = struct{}{}
+		}
+	}
+
+	// A closed account has no post balance and its remaining balance, e.g. wrapped SOL, leaves with the lamports.
+	// Accounts that were closed and re-created in the same transaction are compared as usual.
+	for _, balance := range tx.Meta.PostTokenBalances {
+		delete(closed, indexAccountMap[int(balance.AccountIndex)])
+	}
+
+	var accounts = make(map[string]struct{})
+	for account := range balanceDelta {
+		accounts[account] = struct{}{}
+	}
+	for account := range decodedDelta {
+		accounts[account] = struct{}{}
+	}
+
+	var discrepancies []Discrepancy
+	for account := range accounts {
+		if _, ok := closed[account]; ok {
+			continue
+		}
+		balance := balanceDelta[account]
+		if balance == nil {
+			balance = new(big.Int)
+		}
+		decoded := decodedDelta[account]
+		if decoded == nil {
+			decoded = new(big.Int)
+		}
+		if balance.Cmp(decoded) == 0 {
+			continue
+		}
+		info := tokenAccounts[account]
+		discrepancies = append(discrepancies, Discrepancy{
+			Account:      account,
+			TokenAddress: info.Mint,
+			Owner:        info.Owner,
+			BalanceDelta: balance.String(),
+			DecodedDelta: decoded.String(),
+			Difference:   new(big.Int).Sub(balance, decoded).String(),
+		})
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].Account < discrepancies[j].Account
+	})
+
+	return discrepancies, nil
+}
+
+// ReconcileSignature fetches and decodes the transaction identified by signature, then reconciles its events
+// against its token balances. The decoded events are returned along with the discrepancies.
+func (d *Decoder) ReconcileSignature(ctx context.Context, signature string) ([]Event, []Discrepancy, error) {
+	tx, err := d.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := d.DecodeTransactionEvents(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	discrepancies, err := Reconcile(tx, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, discrepancies, nil
+}
@@ -0,0 +1,75 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// NativeSOLMint is the TokenAddress reported for native lamport transfers.
+// It is not a real mint; it differs from the wrapped SOL mint (So1...112) in the last character.
+const NativeSOLMint = "So11111111111111111111111111111111111111111"
+
+// nativeSOL is the token info attached to native SOL transfers, which need no RPC lookup.
+var nativeSOL = Token{
+	Address:  NativeSOLMint,
+	Decimals: 9,
+	Symbol:   "SOL",
+	Name:     "Solana",
+}
+
+func isSystemProgram(programID string) bool {
+	return programID == common.SystemProgramID.String()
+}
+
+// tryDecodeSystemTransfer decodes system program Transfer and TransferWithSeed instructions.
+// System instructions are bincode encoded, so the discriminator is a little endian u32.
+func tryDecodeSystemTransfer(instruction types.CompiledInstruction, indexAccountMap map[int]string) (*Transfer, error) {
+	if len(instruction.Data) < 4 {
+		return nil, nil
+	}
+
+	instructionType := system.Instruction(binary.LittleEndian.Uint32(instruction.Data[:4]))
+
+	var lamports uint64
+	var sourceAccount, destinationAccount, authorityAccount, transferType string
+
+	switch instructionType {
+	case system.InstructionTransfer:
+		if len(instruction.Data) < 12 || len(instruction.Accounts) < 2 {
+			return nil, fmt.Errorf("%w: system transfer data length %d, accounts length %d", ErrInvalidInstruction, len(instruction.Data), len(instruction.Accounts))
+		}
+		lamports = binary.LittleEndian.Uint64(instruction.Data[4:12])
+		sourceAccount = indexAccountMap[instruction.Accounts[0]]
+		destinationAccount = indexAccountMap[instruction.Accounts[1]]
+		authorityAccount = sourceAccount
+		transferType = "solTransfer"
+
+	case system.InstructionTransferWithSeed:
+		// accounts: funding account (derived), base account (signer), recipient
+		if len(instruction.Data) < 12 || len(instruction.Accounts) < 3 {
+			return nil, fmt.Errorf("%w: system transfer with seed data length %d, accounts length %d", ErrInvalidInstruction, len(instruction.Data), len(instruction.Accounts))
+		}
+		lamports = binary.LittleEndian.Uint64(instruction.Data[4:12])
+		sourceAccount = indexAccountMap[instruction.Accounts[0]]
+		authorityAccount = indexAccountMap[instruction.Accounts[1]]
+		destinationAccount = indexAccountMap[instruction.Accounts[2]]
+		transferType = "solTransferWithSeed"
+
+	default:
+		return nil, nil // Skip instructions that do not move lamports between existing accounts
+	}
+
+	return &Transfer{
+		Type:         transferType,
+		Source:       sourceAccount,
+		Destination:  destinationAccount,
+		Authority:    authorityAccount,
+		TokenAddress: NativeSOLMint,
+		Amount:       fmt.Sprintf("%d", lamports),
+		ProgramID:    indexAccountMap[instruction.ProgramIDIndex],
+	}, nil
+}
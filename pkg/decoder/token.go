@@ -0,0 +1,206 @@
+package decoder
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+)
+
+// maxMultipleAccounts is the most keys a single getMultipleAccounts request accepts.
+const maxMultipleAccounts = 100
+
+// Token describes a mint as shown on decoded transfers.
+type Token struct {
+	Address  string // mint address
+	Decimals uint8
+	Symbol   string
+	Name     string
+}
+
+func (d *Decoder) newToken(ctx context.Context, mintAddress string) (*Token, error) {
+	account, err := d.c.GetAccountInfo(ctx, mintAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get metadata, unless the mint embeds it
+	var metadataData []byte
+	metadataAddress, err := metadataAddressFor(mintAddress, account.Data)
+	if err != nil {
+		return nil, err
+	}
+	if metadataAddress != "" {
+		metadataAccountInfo, err := d.c.GetAccountInfo(ctx, metadataAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metadata account info: %v", err)
+		}
+		metadataData = metadataAccountInfo.Data
+	}
+
+	token, err := parseToken(mintAddress, account.Data, metadataData)
+	return d.completeToken(mintAddress, token, err)
+}
+
+// fetchTokens resolves token info for mints with two rounds of chunked GetMultipleAccounts calls,
+// one for the mint accounts and one for their metadata accounts. If a round fails, the mints are fetched
+// one by one instead. Mints that cannot be resolved are left out of the result.
+// It is the fetch function populateTokens passes to TokenCache.GetMany, which caches the result.
+func (d *Decoder) fetchTokens(ctx context.Context, mints []string) (map[string]*Token, error) {
+	tokens, err := d.fetchTokensBatch(ctx, mints)
+	if err == nil {
+		return tokens, nil
+	}
+	tokens = make(map[string]*Token)
+	for _, mint := range mints {
+		if token, err := d.newToken(ctx, mint); err == nil {
+			tokens[mint] = token
+		}
+	}
+	return tokens, nil
+}
+
+// fetchTokensBatch is fetchTokens without the fallback.
+func (d *Decoder) fetchTokensBatch(ctx context.Context, mints []string) (map[string]*Token, error) {
+	var tokens = make(map[string]*Token)
+	if len(mints) == 0 {
+		return tokens, nil
+	}
+
+	mintAccounts, err := d.getMultipleAccountsData(ctx, mints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint accounts: %w", err)
+	}
+
+	// Only mints without embedded Token-2022 metadata need a second lookup
+	var metadataAddresses []string
+	var metadataIndex = make(map[string]int)
+	for i, mint := range mints {
+		metadataAddress, err := metadataAddressFor(mint, mintAccounts[i])
+		if err != nil {
+			return nil, err
+		}
+		if metadataAddress != "" {
+			metadataIndex[mint] = len(metadataAddresses)
+			metadataAddresses = append(metadataAddresses, metadataAddress)
+		}
+	}
+	metadataAccounts, err := d.getMultipleAccountsData(ctx, metadataAddresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata accounts: %w", err)
+	}
+
+	for i, mint := range mints {
+		var metadataData []byte
+		if j, ok := metadataIndex[mint]; ok {
+			metadataData = metadataAccounts[j]
+		}
+		token, err := parseToken(mint, mintAccounts[i], metadataData)
+		token, err = d.completeToken(mint, token, err)
+		if err != nil {
+			continue
+		}
+		tokens[mint] = token
+	}
+	return tokens, nil
+}
+
+// getMultipleAccountsData returns the data of every address, in order, fetching at most maxMultipleAccounts per call.
+// Missing accounts have nil data.
+func (d *Decoder) getMultipleAccountsData(ctx context.Context, addresses []string) ([][]byte, error) {
+	accountInfos, err := d.getMultipleAccounts(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	data := make([][]byte, 0, len(accountInfos))
+	for _, accountInfo := range accountInfos {
+		data = append(data, accountInfo.Data)
+	}
+	return data, nil
+}
+
+// getMultipleAccounts returns every account, in order, fetching at most maxMultipleAccounts per call.
+// Missing accounts are zero.
+func (d *Decoder) getMultipleAccounts(ctx context.Context, addresses []string) ([]client.AccountInfo, error) {
+	accounts := make([]client.AccountInfo, 0, len(addresses))
+	for start := 0; start < len(addresses); start += maxMultipleAccounts {
+		end := min(start+maxMultipleAccounts, len(addresses))
+		accountInfos, err := d.c.GetMultipleAccounts(ctx, addresses[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(accountInfos) != end-start {
+			return nil, fmt.Errorf("expected %d accounts, got %d", end-start, len(accountInfos))
+		}
+		accounts = append(accounts, accountInfos...)
+	}
+	return accounts, nil
+}
+
+// metadataAddressFor returns the account holding the metadata of a mint: the address of a Token-2022
+// metadata pointer when it points outside the mint, otherwise the Metaplex metadata PDA.
+// It returns an empty address when the mint embeds its metadata.
+func metadataAddressFor(mintAddress string, mintData []byte) (string, error) {
+	extensions := mintExtensions(mintData)
+	if _, ok := extensions[extensionTokenMetadata]; ok {
+		return "", nil
+	}
+	if pointer, ok := metadataPointer(extensions); ok && pointer != mintAddress {
+		return pointer, nil
+	}
+	metadataAddress, err := token_metadata.GetTokenMetaPubkey(common.PublicKeyFromString(mintAddress))
+	if err != nil {
+		return "", fmt.Errorf("failed to find metadata PDA: %v", err)
+	}
+	return metadataAddress.ToBase58(), nil
+}
+
+// parseToken builds token info from the raw mint account and, if there is one, Metaplex metadata account.
+// Symbol and Name are left blank when the mint has no readable metadata; the decimals alone are still useful.
+func parseToken(mintAddress string, mintData, metadataData []byte) (*Token, error) {
+	// The decimals are stored at byte offset 44 in the mint account data
+	if len(mintData) < 45 {
+		return nil, fmt.Errorf("invalid mint account data")
+	}
+
+	token := &Token{
+		Address:  mintAddress,
+		Decimals: mintData[44],
+	}
+
+	embedded, err := embeddedTokenMetadata(mintExtensions(mintData))
+	if err == nil && embedded != nil {
+		token.Symbol = embedded.Symbol
+		token.Name = embedded.Name
+		return token, nil
+	}
+
+	if len(metadataData) > 0 {
+		metadata, err := token_metadata.MetadataDeserialize(metadataData)
+		if err == nil {
+			token.Symbol = metadata.Data.Symbol
+			token.Name = metadata.Data.Name
+		}
+	}
+
+	return token, nil
+}
+
+// completeToken fills in what on-chain data left blank from the local token list, if one is configured.
+// A mint whose account could not be parsed is taken from the list entirely.
+func (d *Decoder) completeToken(mintAddress string, token *Token, err error) (*Token, error) {
+	listed, ok := d.tokenList.Lookup(mintAddress)
+	if err != nil {
+		if ok {
+			return &listed, nil
+		}
+		return nil, err
+	}
+	if ok && token.Symbol == "" && token.Name == "" {
+		token.Symbol = listed.Symbol
+		token.Name = listed.Name
+	}
+	return token, nil
+}
@@ -0,0 +1,91 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+)
+
+// Token-2022 mints store extensions as TLV entries after the base mint,
+// which is padded to the size of a token account and followed by an account type byte.
+const (
+	extensionsOffset = token.TokenAccountSize + 1
+
+	extensionMetadataPointer = 18
+	extensionTokenMetadata   = 19
+)
+
+var errShortTokenMetadata = errors.New("token metadata extension too short")
+
+// tokenMetadata is the part of the Token-2022 TokenMetadata extension we show on transfers.
+type tokenMetadata struct {
+	Name   string
+	Symbol string
+}
+
+// mintExtensions returns the TLV extensions of a Token-2022 mint keyed by extension type.
+// Legacy mints have none.
+func mintExtensions(data []byte) map[uint16][]byte {
+	var extensions = make(map[uint16][]byte)
+	if len(data) <= extensionsOffset {
+		return extensions
+	}
+	for offset := extensionsOffset; offset+4 <= len(data); {
+		extensionType := binary.LittleEndian.Uint16(data[offset : offset+2])
+		length := int(binary.LittleEndian.Uint16(data[offset+2 : offset+4]))
+		offset += 4
+		if extensionType == 0 || offset+length > len(data) {
+			break // uninitialized padding or truncated data
+		}
+		extensions[extensionType] = data[offset : offset+length]
+		offset += length
+	}
+	return extensions
+}
+
+// metadataPointer returns the metadata address a Token-2022 MetadataPointer extension points at, if any.
+func metadataPointer(extensions map[uint16][]byte) (string, bool) {
+	value, ok := extensions[extensionMetadataPointer]
+	// authority (32 bytes) followed by metadata address (32 bytes), all zero when unset
+	if !ok || len(value) < 64 {
+		return "", false
+	}
+	address := common.PublicKeyFromBytes(value[32:64])
+	if address == (common.PublicKey{}) {
+		return "", false
+	}
+	return address.ToBase58(), true
+}
+
+// embeddedTokenMetadata parses the Token-2022 TokenMetadata extension stored in the mint itself.
+func embeddedTokenMetadata(extensions map[uint16][]byte) (*tokenMetadata, error) {
+	value, ok := extensions[extensionTokenMetadata]
+	if !ok {
+		return nil, nil
+	}
+	// update authority (32 bytes), mint (32 bytes), then borsh strings name, symbol, uri
+	offset := 64
+	name, offset, err := borshString(value, offset)
+	if err != nil {
+		return nil, err
+	}
+	symbol, _, err := borshString(value, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenMetadata{Name: name, Symbol: symbol}, nil
+}
+
+func borshString(data []byte, offset int) (string, int, error) {
+	if offset+4 > len(data) {
+		return "", offset, errShortTokenMetadata
+	}
+	length := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+	offset += 4
+	if offset+length > len(data) {
+		return "", offset, errShortTokenMetadata
+	}
+	return string(data[offset : offset+length]), offset + length, nil
+}
@@ -0,0 +1,342 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// MintTo is a MintTo or MintToChecked instruction.
+type MintTo struct {
+	Type         string `json:"type"`
+	TokenAddress string `json:"tokenAddress"`
+	Decimals     uint8  `json:"decimals"`
+	Symbol       string `json:"symbol"`
+	Account      string `json:"account"`
+	Authority    string `json:"authority"`
+	Amount       string `json:"amount"`
+	UiAmount     string `json:"uiAmount"`
+	ProgramID    string `json:"programID"`
+	Position
+}
+
+// Burn is a Burn or BurnChecked instruction.
+type Burn struct {
+	Type         string `json:"type"`
+	TokenAddress string `json:"tokenAddress"`
+	Decimals     uint8  `json:"decimals"`
+	Symbol       string `json:"symbol"`
+	Account      string `json:"account"`
+	Authority    string `json:"authority"`
+	Amount       string `json:"amount"`
+	UiAmount     string `json:"uiAmount"`
+	ProgramID    string `json:"programID"`
+	Position
+}
+
+// Approve is an Approve or ApproveChecked instruction.
+// TokenAddress is only known up front for ApproveChecked.
+type Approve struct {
+	Type         string `json:"type"`
+	TokenAddress string `json:"tokenAddress"`
+	Decimals     uint8  `json:"decimals"`
+	Symbol       string `json:"symbol"`
+	Source       string `json:"source"`
+	Delegate     string `json:"delegate"`
+	Owner        string `json:"owner"`
+	Amount       string `json:"amount"`
+	UiAmount     string `json:"uiAmount"`
+	ProgramID    string `json:"programID"`
+	Position
+}
+
+// Revoke is a Revoke instruction.
+type Revoke struct {
+	Type      string `json:"type"`
+	Source    string `json:"source"`
+	Owner     string `json:"owner"`
+	ProgramID string `json:"programID"`
+	Position
+}
+
+// CloseAccount is a CloseAccount instruction; the remaining lamports go to Destination.
+type CloseAccount struct {
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Destination string `json:"destination"`
+	Owner       string `json:"owner"`
+	ProgramID   string `json:"programID"`
+	Position
+}
+
+// SetAuthority is a SetAuthority instruction. NewAuthority is empty when the authority is removed.
+type SetAuthority struct {
+	Type             string `json:"type"`
+	Account          string `json:"account"` // mint or token account
+	AuthorityType    string `json:"authorityType"`
+	CurrentAuthority string `json:"currentAuthority"`
+	NewAuthority     string `json:"newAuthority"`
+	ProgramID        string `json:"programID"`
+	Position
+}
+
+// FreezeAccount is a FreezeAccount or ThawAccount instruction.
+type FreezeAccount struct {
+	Type         string `json:"type"`
+	Account      string `json:"account"`
+	TokenAddress string `json:"tokenAddress"`
+	Authority    string `json:"authority"`
+	ProgramID    string `json:"programID"`
+	Position
+}
+
+// InitializeAccount is an InitializeAccount, InitializeAccount2 or InitializeAccount3 instruction.
+type InitializeAccount struct {
+	Type         string `json:"type"`
+	Account      string `json:"account"`
+	TokenAddress string `json:"tokenAddress"`
+	Owner        string `json:"owner"`
+	ProgramID    string `json:"programID"`
+	Position
+}
+
+func (e *Transfer) EventType() string          { return e.Type }
+func (e *MintTo) EventType() string            { return e.Type }
+func (e *Burn) EventType() string              { return e.Type }
+func (e *Approve) EventType() string           { return e.Type }
+func (e *Revoke) EventType() string            { return e.Type }
+func (e *CloseAccount) EventType() string      { return e.Type }
+func (e *SetAuthority) EventType() string      { return e.Type }
+func (e *FreezeAccount) EventType() string     { return e.Type }
+func (e *InitializeAccount) EventType() string { return e.Type }
+
+func (e *Transfer) tokenAddress() string { return e.TokenAddress }
+func (e *MintTo) tokenAddress() string   { return e.TokenAddress }
+func (e *Burn) tokenAddress() string     { return e.TokenAddress }
+func (e *Approve) tokenAddress() string  { return e.TokenAddress }
+
+func (e *MintTo) setToken(token *Token) {
+	e.Symbol = token.Symbol
+	e.Decimals = token.Decimals
+	e.UiAmount = uiAmount(e.Amount, token.Decimals)
+}
+
+func (e *Burn) setToken(token *Token) {
+	e.Symbol = token.Symbol
+	e.Decimals = token.Decimals
+	e.UiAmount = uiAmount(e.Amount, token.Decimals)
+}
+
+func (e *Approve) setToken(token *Token) {
+	e.Symbol = token.Symbol
+	e.Decimals = token.Decimals
+	e.UiAmount = uiAmount(e.Amount, token.Decimals)
+}
+
+// authorityTypes names the SetAuthority authority types, including the Token-2022 additions.
+var authorityTypes = []string{
+	"mintTokens",
+	"freezeAccount",
+	"accountOwner",
+	"closeAccount",
+	"transferFeeConfig",
+	"withheldWithdraw",
+	"closeMint",
+	"interestRate",
+	"permanentDelegate",
+	"confidentialTransferMint",
+	"transferHookProgramId",
+	"confidentialTransferFeeConfig",
+	"metadataPointer",
+	"groupPointer",
+	"groupMemberPointer",
+}
+
+func authorityTypeToString(authorityType byte) string {
+	if int(authorityType) < len(authorityTypes) {
+		return authorityTypes[authorityType]
+	}
+	return "unknown"
+}
+
+// tryDecodeTokenInstruction decodes an SPL Token or Token-2022 instruction into an event.
+// It returns nil for instructions it does not cover.
+func tryDecodeTokenInstruction(instruction types.CompiledInstruction, indexAccountMap map[int]string) (Event, error) {
+	if len(instruction.Data) == 0 {
+		return nil, nil
+	}
+
+	data := instruction.Data
+	accounts := instruction.Accounts
+	programID := indexAccountMap[instruction.ProgramIDIndex]
+
+	// account returns the i-th account of the instruction
+	account := func(i int) string {
+		return indexAccountMap[accounts[i]]
+	}
+	// check verifies the minimum data and accounts length of the instruction
+	check := func(name string, dataLen, accountsLen int) error {
+		if len(data) < dataLen || len(accounts) < accountsLen {
+			return fmt.Errorf("%w: %s data length %d, accounts length %d", ErrInvalidInstruction, name, len(data), len(accounts))
+		}
+		return nil
+	}
+
+	switch token.Instruction(data[0]) {
+	case token.InstructionTransfer, token.InstructionTransferChecked, transferFeeExtension:
+		transfer, err := tryDecodeTransfer(instruction, indexAccountMap)
+		if transfer == nil {
+			return nil, err
+		}
+		return transfer, err
+
+	case token.InstructionMintTo, token.InstructionMintToChecked:
+		// data: [tag, u64 amount], followed by u8 decimals for the checked variant
+		event := &MintTo{Type: "mintTo", ProgramID: programID}
+		dataLen := 9
+		if token.Instruction(data[0]) == token.InstructionMintToChecked {
+			event.Type = "mintToChecked"
+			dataLen = 10
+		}
+		if err := check(event.Type, dataLen, 3); err != nil {
+			return nil, err
+		}
+		event.Amount = fmt.Sprintf("%d", binary.LittleEndian.Uint64(data[1:9]))
+		event.TokenAddress = account(0)
+		event.Account = account(1)
+		event.Authority = account(2)
+		return event, nil
+
+	case token.InstructionBurn, token.InstructionBurnChecked:
+		// data: [tag, u64 amount], followed by u8 decimals for the checked variant
+		event := &Burn{Type: "burn", ProgramID: programID}
+		dataLen := 9
+		if token.Instruction(data[0]) == token.InstructionBurnChecked {
+			event.Type = "burnChecked"
+			dataLen = 10
+		}
+		if err := check(event.Type, dataLen, 3); err != nil {
+			return nil, err
+		}
+		event.Amount = fmt.Sprintf("%d", binary.LittleEndian.Uint64(data[1:9]))
+		event.Account = account(0)
+		event.TokenAddress = account(1)
+		event.Authority = account(2)
+		return event, nil
+
+	case token.InstructionApprove:
+		if err := check("approve", 9, 3); err != nil {
+			return nil, err
+		}
+		return &Approve{
+			Type:      "approve",
+			Source:    account(0),
+			Delegate:  account(1),
+			Owner:     account(2),
+			Amount:    fmt.Sprintf("%d", binary.LittleEndian.Uint64(data[1:9])),
+			ProgramID: programID,
+		}, nil
+
+	case token.InstructionApproveChecked:
+		if err := check("approveChecked", 10, 4); err != nil {
+			return nil, err
+		}
+		return &Approve{
+			Type:         "approveChecked",
+			Source:       account(0),
+			TokenAddress: account(1),
+			Delegate:     account(2),
+			Owner:        account(3),
+			Amount:       fmt.Sprintf("%d", binary.LittleEndian.Uint64(data[1:9])),
+			ProgramID:    programID,
+		}, nil
+
+	case token.InstructionRevoke:
+		if err := check("revoke", 1, 2); err != nil {
+			return nil, err
+		}
+		return &Revoke{
+			Type:      "revoke",
+			Source:    account(0),
+			Owner:     account(1),
+			ProgramID: programID,
+		}, nil
+
+	case token.InstructionCloseAccount:
+		if err := check("closeAccount", 1, 3); err != nil {
+			return nil, err
+		}
+		return &CloseAccount{
+			Type:        "closeAccount",
+			Account:     account(0),
+			Destination: account(1),
+			Owner:       account(2),
+			ProgramID:   programID,
+		}, nil
+
+	case token.InstructionSetAuthority:
+		// data: [6, authority type, COption<Pubkey> as 1 byte tag + 32 bytes]
+		if err := check("setAuthority", 3, 2); err != nil {
+			return nil, err
+		}
+		event := &SetAuthority{
+			Type:             "setAuthority",
+			Account:          account(0),
+			AuthorityType:    authorityTypeToString(data[1]),
+			CurrentAuthority: account(1),
+			ProgramID:        programID,
+		}
+		if data[2] == 1 {
+			if err := check(event.Type, 35, 2); err != nil {
+				return nil, err
+			}
+			event.NewAuthority = common.PublicKeyFromBytes(data[3:35]).ToBase58()
+		}
+		return event, nil
+
+	case token.InstructionFreezeAccount, token.InstructionThawAccount:
+		event := &FreezeAccount{Type: "freezeAccount", ProgramID: programID}
+		if token.Instruction(data[0]) == token.InstructionThawAccount {
+			event.Type = "thawAccount"
+		}
+		if err := check(event.Type, 1, 3); err != nil {
+			return nil, err
+		}
+		event.Account = account(0)
+		event.TokenAddress = account(1)
+		event.Authority = account(2)
+		return event, nil
+
+	case token.InstructionInitializeAccount:
+		if err := check("initializeAccount", 1, 3); err != nil {
+			return nil, err
+		}
+		return &InitializeAccount{
+			Type:         "initializeAccount",
+			Account:      account(0),
+			TokenAddress: account(1),
+			Owner:        account(2),
+			ProgramID:    programID,
+		}, nil
+
+	case token.InstructionInitializeAccount2, token.InstructionInitializeAccount3:
+		// the owner is passed in instruction data rather than as an account
+		event := &InitializeAccount{Type: "initializeAccount2", ProgramID: programID}
+		if token.Instruction(data[0]) == token.InstructionInitializeAccount3 {
+			event.Type = "initializeAccount3"
+		}
+		if err := check(event.Type, 33, 2); err != nil {
+			return nil, err
+		}
+		event.Account = account(0)
+		event.TokenAddress = account(1)
+		event.Owner = common.PublicKeyFromBytes(data[1:33]).ToBase58()
+		return event, nil
+
+	default:
+		return nil, nil // Skip unsupported instructions
+	}
+}
@@ -0,0 +1,63 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// TokenList is a local list of token symbols and names, used for mints without on-chain metadata.
+type TokenList struct {
+	tokens map[string]Token
+}
+
+type tokenListEntry struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// LoadTokenList reads a JSON token list from path. Both the Solana token-list format,
+// an object whose "tokens" field holds the entries, and a bare array of entries are accepted.
+// Each entry needs an "address" and may carry "symbol", "name" and "decimals".
+func LoadTokenList(path string) (*TokenList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token list: %w", err)
+	}
+
+	var entries []tokenListEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var list struct {
+			Tokens []tokenListEntry `json:"tokens"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse token list %s: %w", path, err)
+		}
+		entries = list.Tokens
+	}
+
+	tokenList := &TokenList{tokens: make(map[string]Token, len(entries))}
+	for _, entry := range entries {
+		if entry.Address == "" {
+			continue
+		}
+		tokenList.tokens[entry.Address] = Token{
+			Address:  entry.Address,
+			Decimals: entry.Decimals,
+			Symbol:   entry.Symbol,
+			Name:     entry.Name,
+		}
+	}
+	return tokenList, nil
+}
+
+// Lookup returns the list entry for mint.
+func (l *TokenList) Lookup(mint string) (Token, bool) {
+	if l == nil {
+		return Token{}, false
+	}
+	token, ok := l.tokens[mint]
+	return token, ok
+}
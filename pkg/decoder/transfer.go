@@ -0,0 +1,131 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+// Token-2022 TransferFeeExtension instructions are prefixed with this byte,
+// followed by a one-byte sub-instruction.
+const (
+	transferFeeExtension   = 26
+	transferCheckedWithFee = 1
+)
+
+// Transfer is a single token movement decoded from a transaction.
+type Transfer struct {
+	Type             string `json:"type"`
+	TokenAddress     string `json:"tokenAddress"`
+	Decimals         uint8  `json:"decimals"`
+	Symbol           string `json:"symbol"`
+	Name             string `json:"name"`
+	Authority        string `json:"authority"`
+	Source           string `json:"source"`
+	SourceOwner      string `json:"sourceOwner"` // wallet owning Source
+	Destination      string `json:"destination"`
+	DestinationOwner string `json:"destinationOwner"` // wallet owning Destination
+	Amount           string `json:"amount"`
+	UiAmount         string `json:"uiAmount"`
+	Fee              string `json:"fee,omitempty"`   // Token-2022 transfer fee withheld from Amount
+	UiFee            string `json:"uiFee,omitempty"` // Fee scaled by Decimals
+	ProgramID        string `json:"programID"`       // token program that executed the instruction
+	Position
+}
+
+// setToken copies token info onto the transfer and fills in UiAmount.
+func (t *Transfer) setToken(token *Token) {
+	t.Symbol = token.Symbol
+	t.Name = token.Name
+	t.Decimals = token.Decimals
+	t.UiAmount = uiAmount(t.Amount, token.Decimals)
+	if t.Fee != "" {
+		t.UiFee = uiAmount(t.Fee, token.Decimals)
+	}
+}
+
+// isTokenProgram reports whether programID is the legacy SPL Token program or Token-2022.
+func isTokenProgram(programID string) bool {
+	return programID == common.TokenProgramID.String() || programID == common.Token2022ProgramID.String()
+}
+
+func tryDecodeTransfer(instruction types.CompiledInstruction, indexAccountMap map[int]string) (*Transfer, error) {
+	if len(instruction.Data) == 0 {
+		return nil, nil
+	}
+
+	instructionType := instruction.Data[0]
+
+	var amount uint64
+	var fee string
+	var sourceAccount, destinationAccount, authorityAccount, mintAddress string
+
+	switch instructionType {
+	case 3: // Transfer
+		if len(instruction.Data) < 9 || len(instruction.Accounts) < 3 {
+			return nil, fmt.Errorf("%w: transfer data length %d, accounts length %d", ErrInvalidInstruction, len(instruction.Data), len(instruction.Accounts))
+		}
+		amount = binary.LittleEndian.Uint64(instruction.Data[1:9])
+		sourceAccount = indexAccountMap[instruction.Accounts[0]]
+		destinationAccount = indexAccountMap[instruction.Accounts[1]]
+		authorityAccount = indexAccountMap[instruction.Accounts[2]]
+
+		// We don't have the mint address for regular transfers, so we'll leave it empty
+		mintAddress = ""
+
+	case 12: // TransferChecked
+		if len(instruction.Data) < 10 || len(instruction.Accounts) < 4 {
+			return nil, fmt.Errorf("%w: transfer checked data length %d, accounts length %d", ErrInvalidInstruction, len(instruction.Data), len(instruction.Accounts))
+		}
+		amount = binary.LittleEndian.Uint64(instruction.Data[1:9])
+		sourceAccount = indexAccountMap[instruction.Accounts[0]]
+		mintAddress = indexAccountMap[instruction.Accounts[1]]
+		destinationAccount = indexAccountMap[instruction.Accounts[2]]
+		authorityAccount = indexAccountMap[instruction.Accounts[3]]
+
+	case transferFeeExtension: // Token-2022 TransferFeeExtension
+		if len(instruction.Data) < 2 || instruction.Data[1] != transferCheckedWithFee {
+			return nil, nil // Only TransferCheckedWithFee moves tokens
+		}
+		if len(instruction.Data) < 19 || len(instruction.Accounts) < 4 {
+			return nil, fmt.Errorf("%w: transfer checked with fee data length %d, accounts length %d", ErrInvalidInstruction, len(instruction.Data), len(instruction.Accounts))
+		}
+		amount = binary.LittleEndian.Uint64(instruction.Data[2:10])
+		fee = fmt.Sprintf("%d", binary.LittleEndian.Uint64(instruction.Data[11:19]))
+		sourceAccount = indexAccountMap[instruction.Accounts[0]]
+		mintAddress = indexAccountMap[instruction.Accounts[1]]
+		destinationAccount = indexAccountMap[instruction.Accounts[2]]
+		authorityAccount = indexAccountMap[instruction.Accounts[3]]
+
+	default:
+		return nil, nil // Skip unsupported instructions
+	}
+
+	transfer := &Transfer{
+		Type:         instructionTypeToString(instructionType),
+		Source:       sourceAccount,
+		Destination:  destinationAccount,
+		Authority:    authorityAccount,
+		TokenAddress: mintAddress,
+		Amount:       fmt.Sprintf("%d", amount),
+		Fee:          fee,
+		ProgramID:    indexAccountMap[instruction.ProgramIDIndex],
+	}
+
+	return transfer, nil
+}
+
+func instructionTypeToString(instructionType byte) string {
+	switch instructionType {
+	case 3:
+		return "transfer"
+	case 12:
+		return "transferChecked"
+	case transferFeeExtension:
+		return "transferCheckedWithFee"
+	default:
+		return "unknown"
+	}
+}
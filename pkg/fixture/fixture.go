@@ -0,0 +1,276 @@
+// Package fixture decodes transactions offline from getTransaction responses saved on disk.
+//
+// A fixture directory holds one file per transaction, named <signature>.json, with the JSON-RPC response
+// to getTransaction requested with base64 encoding and maxSupportedTransactionVersion 0.
+// Token info comes from tokens.json, a token cache file as written by decoder.TokenCache.Save,
+// and accounts the decoder looks up, such as token accounts whose owner is not in the token balances,
+// come from accounts.json, an object mapping addresses to getAccountInfo values. Both files are optional.
+//
+// The fixture client speaks the RPC protocol to the decoder, so the decoder runs unchanged
+// and the SDK's own response parsing is exercised too.
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/decoder"
+)
+
+// File names inside a fixture directory.
+const (
+	TokensFile   = "tokens.json"
+	AccountsFile = "accounts.json"
+)
+
+// offlineEndpoint is never dialed; requests are answered by Transport.
+const offlineEndpoint = "http://fixture.invalid"
+
+// JSON-RPC error codes for requests a fixture cannot answer.
+const (
+	errCodeMethodNotFound = -32601
+	errCodeInvalidParams  = -32602
+)
+
+// Transport is an http.RoundTripper that answers JSON-RPC requests from the fixture files in Dir.
+// It serves getTransaction, getAccountInfo and getMultipleAccounts; other methods fail.
+type Transport struct {
+	Dir string
+}
+
+// NewClient returns a client that reads from the fixture directory dir instead of a node.
+func NewClient(dir string) *client.Client {
+	return client.New(
+		rpc.WithEndpoint(offlineEndpoint),
+		rpc.WithHTTPClient(&http.Client{Transport: &Transport{Dir: dir}}),
+	)
+}
+
+// NewDecoder returns a decoder that reads transactions, token info and accounts from the fixture directory dir.
+// Entries in tokens.json should be saved without an expiry, as by a cache loaded with a ttl of zero.
+func NewDecoder(dir string, opts ...decoder.Option) (*decoder.Decoder, error) {
+	// Never saved, so decoding does not write to the fixture
+	tokens, err := decoder.LoadTokenCache(filepath.Join(dir, TokensFile), 0)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]decoder.Option{decoder.WithTokenCache(tokens)}, opts...)
+	return decoder.New(NewClient(dir), opts...), nil
+}
+
+// LoadTransaction reads the saved getTransaction response at path, which must be named <signature>.json.
+func LoadTransaction(ctx context.Context, path string) (*client.Transaction, error) {
+	dir, name := filepath.Split(path)
+	signature := strings.TrimSuffix(name, ".json")
+	tx, err := NewClient(dir).GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: %s", decoder.ErrTransactionNotFound, signature)
+	}
+	return tx, nil
+}
+
+// Signatures returns the signatures of the transactions saved in dir, in file name order.
+func Signatures(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var signatures []string
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if name == TokensFile || name == AccountsFile || strings.HasSuffix(name, ".golden.json") {
+			continue
+		}
+		signatures = append(signatures, strings.TrimSuffix(name, ".json"))
+	}
+	return signatures, nil
+}
+
+// SaveTransaction fetches the transaction identified by signature with c and saves the response in dir.
+func SaveTransaction(ctx context.Context, c *client.Client, dir, signature string) error {
+	body, err := c.RpcClient.Call(ctx, "getTransaction", signature, map[string]any{
+		"encoding":                       "base64",
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", signature, err)
+	}
+	var res rpc.JsonRpcResponse[json.RawMessage]
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("invalid getTransaction response: %w", err)
+	}
+	if res.Error != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", signature, res.Error)
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		return fmt.Errorf("%w: %s", decoder.ErrTransactionNotFound, signature)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return fmt.Errorf("invalid getTransaction response: %w", err)
+	}
+	out.WriteByte('\n')
+	if err := os.WriteFile(filepath.Join(dir, signature+".json"), out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to save transaction: %w", err)
+	}
+	return nil
+}
+
+type request struct {
+	ID     uint64            `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// RoundTrip answers a JSON-RPC request from the fixture files.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var r request
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("fixture: invalid request: %w", err)
+	}
+
+	res := rpc.JsonRpcResponse[any]{JsonRpc: "2.0", Id: r.ID}
+	result, err := t.answer(r)
+	var rpcErr *rpc.JsonRpcError
+	switch {
+	case errors.As(err, &rpcErr):
+		res.Error = rpcErr
+	case err != nil:
+		return nil, err
+	default:
+		res.Result = result
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+func (t *Transport) answer(r request) (any, error) {
+	switch r.Method {
+	case "getTransaction":
+		var signature string
+		if err := unmarshalParam(r.Params, 0, &signature); err != nil {
+			return nil, err
+		}
+		return t.transaction(signature)
+	case "getAccountInfo":
+		var address string
+		if err := unmarshalParam(r.Params, 0, &address); err != nil {
+			return nil, err
+		}
+		accounts, err := t.accounts()
+		if err != nil {
+			return nil, err
+		}
+		return rpc.ValueWithContext[json.RawMessage]{Value: accountOrNull(accounts, address)}, nil
+	case "getMultipleAccounts":
+		var addresses []string
+		if err := unmarshalParam(r.Params, 0, &addresses); err != nil {
+			return nil, err
+		}
+		accounts, err := t.accounts()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]json.RawMessage, len(addresses))
+		for i, address := range addresses {
+			values[i] = accountOrNull(accounts, address)
+		}
+		return rpc.ValueWithContext[[]json.RawMessage]{Value: values}, nil
+	default:
+		return nil, &rpc.JsonRpcError{Code: errCodeMethodNotFound, Message: fmt.Sprintf("%s is not available offline", r.Method)}
+	}
+}
+
+// transaction returns the saved result for signature, or nil if there is no fixture for it.
+func (t *Transport) transaction(signature string) (json.RawMessage, error) {
+	data, err := os.ReadFile(filepath.Join(t.Dir, signature+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return json.RawMessage("null"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixture: %w", err)
+	}
+
+	// Accept the whole JSON-RPC response as well as just its result
+	var res struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("fixture: invalid transaction %s: %w", signature, err)
+	}
+	result := res.Result
+	if len(result) == 0 {
+		result = data
+	}
+
+	var tx struct {
+		Transaction []string `json:"transaction"`
+	}
+	if err := json.Unmarshal(result, &tx); err != nil || len(tx.Transaction) != 2 || tx.Transaction[1] != "base64" {
+		return nil, fmt.Errorf("fixture: transaction %s must be saved with base64 encoding", signature)
+	}
+	return result, nil
+}
+
+// accounts reads accounts.json, which may be missing.
+func (t *Transport) accounts() (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(filepath.Join(t.Dir, AccountsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixture: %w", err)
+	}
+	var accounts map[string]json.RawMessage
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("fixture: invalid %s: %w", AccountsFile, err)
+	}
+	return accounts, nil
+}
+
+func accountOrNull(accounts map[string]json.RawMessage, address string) json.RawMessage {
+	if account, ok := accounts[address]; ok {
+		return account
+	}
+	return json.RawMessage("null")
+}
+
+func unmarshalParam(params []json.RawMessage, i int, v any) error {
+	if i >= len(params) {
+		return &rpc.JsonRpcError{Code: errCodeInvalidParams, Message: "missing params"}
+	}
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return &rpc.JsonRpcError{Code: errCodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
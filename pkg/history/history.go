@@ -0,0 +1,114 @@
+// Package history crawls the transaction history of an address backwards in time
+// and decodes the transfers that involve it.
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+
+	"solana-starter/pkg/decoder"
+)
+
+// DefaultPageSize is the number of signatures requested per getSignaturesForAddress call.
+const DefaultPageSize = 1000
+
+// Record is a transfer in the history of an address.
+type Record struct {
+	Signature string
+	Slot      uint64
+	BlockTime *int64
+	Transfer  *decoder.Transfer
+}
+
+// Config bounds a crawl. All fields are optional.
+type Config struct {
+	// Before starts the crawl at the transaction before this signature instead of the latest one.
+	Before string
+	// Until stops the crawl when this signature is reached; it is not itself decoded.
+	Until string
+	// Limit is the maximum number of signatures to decode, zero for no limit.
+	Limit int
+	// PageSize is the number of signatures fetched per page, DefaultPageSize when zero.
+	PageSize int
+}
+
+// Crawler pages through the signatures of an address and decodes each transaction.
+type Crawler struct {
+	c *client.Client
+	d *decoder.Decoder
+}
+
+// New returns a Crawler that lists signatures with c and decodes them with d.
+func New(c *client.Client, d *decoder.Decoder) *Crawler {
+	return &Crawler{c: c, d: d}
+}
+
+// Crawl sends to out, newest first, every transfer in the history of address whose source, destination,
+// their owners or authority is address. Address may be a wallet or a token account.
+// Failed transactions are skipped. It returns the last signature it decoded, which can be passed
+// as Config.Before to continue an interrupted crawl. Crawl does not close out.
+func (cr *Crawler) Crawl(ctx context.Context, address string, cfg Config, out chan<- Record) (string, error) {
+	pageSize := cfg.PageSize
+	if pageSize <= 0 || pageSize > DefaultPageSize {
+		pageSize = DefaultPageSize
+	}
+
+	before := cfg.Before
+	var decoded int
+	for {
+		signatures, err := cr.c.GetSignaturesForAddressWithConfig(ctx, address, client.GetSignaturesForAddressConfig{
+			Limit:  pageSize,
+			Before: before,
+			Until:  cfg.Until,
+		})
+		if err != nil {
+			return before, fmt.Errorf("failed to get signatures before %q: %w", before, err)
+		}
+		if len(signatures) == 0 {
+			return before, nil
+		}
+
+		for _, signature := range signatures {
+			if cfg.Limit > 0 && decoded >= cfg.Limit {
+				return before, nil
+			}
+			if signature.Err == nil {
+				transfers, err := cr.d.DecodeSignature(ctx, signature.Signature)
+				if err != nil {
+					return before, fmt.Errorf("failed to decode %s: %w", signature.Signature, err)
+				}
+				for _, transfer := range transfers {
+					if !involves(transfer, address) {
+						continue
+					}
+					select {
+					case out <- Record{
+						Signature: signature.Signature,
+						Slot:      signature.Slot,
+						BlockTime: signature.BlockTime,
+						Transfer:  transfer,
+					}:
+					case <-ctx.Done():
+						return before, ctx.Err()
+					}
+				}
+			}
+			decoded++
+			before = signature.Signature
+		}
+
+		if len(signatures) < pageSize {
+			return before, nil
+		}
+	}
+}
+
+func involves(transfer *decoder.Transfer, address string) bool {
+	switch address {
+	case transfer.Source, transfer.Destination, transfer.SourceOwner, transfer.DestinationOwner, transfer.Authority:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/fixture"
+)
+
+const (
+	fixtureDir = "../decoder/testdata"
+
+	// Two token transfers signed by tokenOwner, and a SOL transfer from solSender, captured on devnet
+	tokenTransferSignature = "4fSTSDTTuYa1XXAFxFenuY3SoZWUwCzpMq7kUiya1zW6uqqh6C76GFqTQ3wvegEbZhbPJyr33iDAbieQVWCtVXmf"
+	solTransferSignature   = "4Dj8Xbs7L6z7pbNp5eGZXLmYZLwePPRVTfunjx2EWDc4nwtVYRq4YqduiFKXR23cGqmbF6LHoubGnKa7gCozstGF"
+	tokenOwner             = "RNfp4xTbBb4C3kcv2KqtAj8mu4YhMHxqm1Skg9uchZ7"
+	solSender              = "27kVX7JpPZ1bsrSckbR76mV6GeRqtrjoddubfg2zBpHZ"
+
+	// failedSignature has no fixture, so decoding it fails the test
+	failedSignature = "failed"
+)
+
+// node is a stub RPC node answering getSignaturesForAddress from signatures, newest first.
+type node struct {
+	*httptest.Server
+	signatures []rpc.SignatureWithStatus
+
+	mu    sync.Mutex
+	pages []string // the before signature of every page asked for
+}
+
+func newNode(t *testing.T, signatures []rpc.SignatureWithStatus) *node {
+	t.Helper()
+	nd := &node{signatures: signatures}
+	nd.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "getSignaturesForAddress" || len(req.Params) < 2 {
+			t.Errorf("unexpected request %+v: %v", req, err)
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var cfg rpc.GetSignaturesForAddressConfig
+		if err := json.Unmarshal(req.Params[1], &cfg); err != nil {
+			t.Errorf("invalid config: %v", err)
+			return
+		}
+		nd.mu.Lock()
+		nd.pages = append(nd.pages, cfg.Before)
+		nd.mu.Unlock()
+
+		page := []rpc.SignatureWithStatus{}
+		started := cfg.Before == ""
+		for _, signature := range nd.signatures {
+			if signature.Signature == cfg.Until || len(page) == cfg.Limit {
+				break
+			}
+			if started {
+				page = append(page, signature)
+			}
+			started = started || signature.Signature == cfg.Before
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rpc.JsonRpcResponse[rpc.GetSignaturesForAddress]{JsonRpc: "2.0", Id: req.ID, Result: page})
+	}))
+	t.Cleanup(nd.Close)
+	return nd
+}
+
+// history lists the fixtures newest first, with a failed transaction between them.
+var history = []rpc.SignatureWithStatus{
+	{Signature: tokenTransferSignature, Slot: 3},
+	{Signature: failedSignature, Slot: 2, Err: map[string]any{"InstructionError": []any{0, "Custom"}}},
+	{Signature: solTransferSignature, Slot: 1},
+}
+
+// crawl runs a crawl over the node and returns the records it sent along with the last signature.
+func crawl(t *testing.T, nd *node, address string, cfg Config) ([]Record, string) {
+	t.Helper()
+	d, err := fixture.NewDecoder(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr := New(client.NewClient(nd.URL), d)
+
+	out := make(chan Record)
+	type result struct {
+		last string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		last, err := cr.Crawl(context.Background(), address, cfg, out)
+		done <- result{last, err}
+		close(out)
+	}()
+	var records []Record
+	for record := range out {
+		records = append(records, record)
+	}
+	res := <-done
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	return records, res.last
+}
+
+// signatures returns the signature of every record.
+func signatures(records []Record) []string {
+	var signatures []string
+	for _, record := range records {
+		signatures = append(signatures, record.Signature)
+	}
+	return signatures
+}
+
+func TestCrawl(t *testing.T) {
+	tests := []struct {
+		address string
+		want    []string
+	}{
+		{tokenOwner, []string{tokenTransferSignature, tokenTransferSignature}},
+		{solSender, []string{solTransferSignature}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			nd := newNode(t, history)
+			records, last := crawl(t, nd, tt.address, Config{PageSize: 2})
+			if got := signatures(records); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got transfers of %v, want %v", got, tt.want)
+			}
+			for _, record := range records {
+				if !involves(record.Transfer, tt.address) {
+					t.Errorf("got a transfer not involving %s: %+v", tt.address, record.Transfer)
+				}
+			}
+			if last != solTransferSignature {
+				t.Errorf("last signature is %s, want %s", last, solTransferSignature)
+			}
+			// The second page starts before the last signature of the first, and is short, so it is the last
+			if want := []string{"", failedSignature}; fmt.Sprint(nd.pages) != fmt.Sprint(want) {
+				t.Errorf("asked for pages before %q, want %q", nd.pages, want)
+			}
+		})
+	}
+}
+
+func TestCrawlResume(t *testing.T) {
+	nd := newNode(t, history)
+	records, last := crawl(t, nd, tokenOwner, Config{Limit: 2})
+	if len(records) != 2 || last != failedSignature {
+		t.Fatalf("got %d records up to %s, want 2 up to %s", len(records), last, failedSignature)
+	}
+
+	// Continuing from the last signature finds the rest of the history
+	records, last = crawl(t, nd, solSender, Config{Before: last})
+	if got, want := signatures(records), []string{solTransferSignature}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got transfers of %v, want %v", got, want)
+	}
+	if last != solTransferSignature {
+		t.Errorf("last signature is %s, want %s", last, solTransferSignature)
+	}
+
+	// Until stops before the signature it names
+	records, last = crawl(t, nd, tokenOwner, Config{Until: failedSignature})
+	if len(records) != 2 || last != tokenTransferSignature {
+		t.Errorf("got %d records up to %s, want 2 up to %s", len(records), last, tokenTransferSignature)
+	}
+}
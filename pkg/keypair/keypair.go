@@ -0,0 +1,226 @@
+// Package keypair reads and writes signer keys in the formats the Solana tools use:
+// the 64-byte JSON array of solana-keygen, base58 private keys as exported by wallets, and BIP39 mnemonics.
+package keypair
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/pkg/hdwallet"
+	"github.com/blocto/solana-go-sdk/types"
+	"github.com/mr-tron/base58"
+	"github.com/tyler-smith/go-bip39"
+
+	"solana-starter/pkg/keystore"
+	"solana-starter/pkg/pathutil"
+	"solana-starter/pkg/prompt"
+)
+
+// DefaultDerivationPath is the BIP44 path of the first account of wallets such as Phantom.
+const DefaultDerivationPath = `m/44'/501'/0'/0'`
+
+var (
+	ErrUnsupportedSource = errors.New("keypair: unsupported signer source")
+	ErrInvalidKey        = errors.New("keypair: invalid key")
+	ErrInvalidMnemonic   = errors.New("keypair: invalid mnemonic")
+)
+
+// LoadSigner loads the account named by source, which is one of
+//
+//	/path/to/id.json, file:/path/to/id.json  a file holding a JSON array, base58 key or mnemonic
+//	base58:<private key>                     an inline base58 private key
+//	mnemonic:<words>                         an inline BIP39 mnemonic
+//	stdin:, -                                a JSON array, base58 key or mnemonic read from standard input
+//	keystore:<name>, <name>                  an account in the encrypted keystore, see package keystore
+//
+// A bare name, without a path separator or extension, is looked up in the keystore unless a file of
+// that name exists.
+// Mnemonics are turned into keys the way solana-keygen does unless the source ends in
+// ?derivation=<path>, e.g. ?derivation=m/44'/501'/0'/0' for the first Phantom account.
+// A leading ~/ in file paths is expanded to the home directory.
+func LoadSigner(source string) (types.Account, error) {
+	source, derivation := splitDerivation(source)
+	scheme, rest, _ := strings.Cut(source, ":")
+	switch {
+	case source == "-" || scheme == "stdin":
+		data, err := readStdin()
+		if err != nil {
+			return types.Account{}, err
+		}
+		return Parse(data, derivation)
+	case scheme == "base58":
+		return FromBase58(rest)
+	case scheme == "mnemonic":
+		return FromMnemonic(rest, "", derivation)
+	case scheme == "file":
+		return ReadFile(strings.TrimPrefix(rest, "//"), derivation)
+	case scheme == "keystore":
+		return keystore.Load(rest)
+	case strings.Contains(source, "://") || scheme == "prompt" || scheme == "usb":
+		return types.Account{}, fmt.Errorf("%w: %s", ErrUnsupportedSource, source)
+	case isKeystoreName(source):
+		return keystore.Load(source)
+	default:
+		return ReadFile(source, derivation)
+	}
+}
+
+// isKeystoreName reports whether source is a bare name rather than a path to an existing file.
+func isKeystoreName(source string) bool {
+	if source == "" || strings.ContainsAny(source, `/\:.`) {
+		return false
+	}
+	_, err := os.Stat(source)
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// splitDerivation removes a trailing ?derivation=<path> from source.
+func splitDerivation(source string) (string, string) {
+	i := strings.LastIndex(source, "?")
+	if i < 0 {
+		return source, ""
+	}
+	query, err := url.ParseQuery(source[i+1:])
+	if err != nil || !query.Has("derivation") {
+		return source, ""
+	}
+	derivation := query.Get("derivation")
+	if derivation == "" {
+		derivation = DefaultDerivationPath
+	}
+	return source[:i], derivation
+}
+
+func readStdin() ([]byte, error) {
+	// One line, left unbuffered so a passphrase prompted for next can still be read from stdin
+	line, err := prompt.Secret("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key from stdin: %w", err)
+	}
+	return []byte(line), nil
+}
+
+// ReadFile reads an account from a file holding a JSON array, a base58 private key or a mnemonic.
+// derivation is the BIP44 path used if the file holds a mnemonic; see FromMnemonic.
+func ReadFile(path, derivation string) (types.Account, error) {
+	data, err := os.ReadFile(pathutil.ExpandHome(path))
+	if err != nil {
+		return types.Account{}, fmt.Errorf("failed to read keypair: %w", err)
+	}
+	account, err := Parse(data, derivation)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return account, nil
+}
+
+// Parse detects the format of data: a JSON array, a mnemonic of several words, or else a base58 private key.
+func Parse(data []byte, derivation string) (types.Account, error) {
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "["):
+		return FromJSON([]byte(text))
+	case len(strings.Fields(text)) > 1:
+		return FromMnemonic(text, "", derivation)
+	default:
+		return FromBase58(text)
+	}
+}
+
+// FromJSON decodes the 64-byte JSON array written by solana-keygen: the ed25519 seed followed by the public key.
+func FromJSON(data []byte) (types.Account, error) {
+	var key []byte
+	if err := json.Unmarshal(data, &key); err != nil {
+		return types.Account{}, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return fromBytes(key)
+}
+
+// FromBase58 decodes a base58 private key, as exported by Phantom and Solflare.
+func FromBase58(key string) (types.Account, error) {
+	b, err := base58.Decode(strings.TrimSpace(key))
+	if err != nil {
+		return types.Account{}, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return fromBytes(b)
+}
+
+// fromBytes builds an account from a 64-byte private key, checking that its public half matches the seed.
+func fromBytes(key []byte) (types.Account, error) {
+	account, err := types.AccountFromBytes(key)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	derived, err := types.AccountFromSeed(key[:32])
+	if err != nil || derived.PublicKey != account.PublicKey {
+		return types.Account{}, fmt.Errorf("%w: public key does not match the private key", ErrInvalidKey)
+	}
+	return account, nil
+}
+
+// FromMnemonic derives an account from a BIP39 mnemonic and optional passphrase.
+// With an empty derivation the key is the first 32 bytes of the seed, as solana-keygen recovers it;
+// otherwise it is derived along the BIP44 path, as wallets such as Phantom do.
+func FromMnemonic(mnemonic, passphrase, derivation string) (types.Account, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+	if derivation == "" {
+		return types.AccountFromSeed(seed[:32])
+	}
+	key, err := hdwallet.Derived(derivation, seed)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("%w: derivation path %q: %v", ErrInvalidMnemonic, derivation, err)
+	}
+	return types.AccountFromSeed(key.PrivateKey)
+}
+
+// NewMnemonic generates a 12-word mnemonic, the length solana-keygen uses.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// EncodeJSON returns the account's private key as the JSON array solana-keygen writes.
+func EncodeJSON(account types.Account) []byte {
+	// json.Marshal would encode the bytes as base64
+	numbers := make([]int, len(account.PrivateKey))
+	for i, b := range account.PrivateKey {
+		numbers[i] = int(b)
+	}
+	data, _ := json.Marshal(numbers)
+	return data
+}
+
+// EncodeBase58 returns the account's private key in base58, the format wallets import.
+func EncodeBase58(account types.Account) string {
+	return base58.Encode(account.PrivateKey)
+}
+
+// WriteFile writes the account to path as a solana-keygen JSON array, readable only by the owner.
+// Like solana-keygen it refuses to overwrite an existing file; the error then wraps os.ErrExist.
+func WriteFile(path string, account types.Account) error {
+	path = pathutil.ExpandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create keypair directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create keypair: %w", err)
+	}
+	if _, err := f.Write(EncodeJSON(account)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write keypair: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,354 @@
+// Package keystore keeps named signer accounts in a passphrase-encrypted file, so private keys need not
+// live in source or in plaintext keypair files.
+//
+// The passphrase is stretched with scrypt into an AES-256-GCM key; every account is sealed with its own nonce
+// and bound to its name and public key. Names and public keys are stored in the clear so a keystore can be
+// listed without unlocking it.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/types"
+	"golang.org/x/crypto/scrypt"
+
+	"solana-starter/pkg/pathutil"
+	"solana-starter/pkg/prompt"
+)
+
+// DefaultPath is the keystore used unless SOLANA_STARTER_KEYSTORE names another.
+const DefaultPath = "~/.config/solana-starter/keystore.json"
+
+// Environment variables.
+const (
+	EnvPath       = "SOLANA_STARTER_KEYSTORE"
+	EnvPassphrase = "SOLANA_STARTER_KEYSTORE_PASSPHRASE"
+)
+
+const (
+	version = 1
+	// scrypt parameters recommended for interactive logins in 2017, taking about 100ms
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	saltLength   = 32
+	keyLength    = 32
+	cipherAESGCM = "aes-256-gcm"
+)
+
+var (
+	ErrNotFound         = errors.New("keystore: no such account")
+	ErrExists           = errors.New("keystore: account already exists")
+	ErrLocked           = errors.New("keystore: locked")
+	ErrWrongPassphrase  = errors.New("keystore: wrong passphrase or corrupted keystore")
+	ErrInvalidName      = errors.New("keystore: invalid account name")
+	ErrUnsupportedStore = errors.New("keystore: unsupported keystore format")
+	ErrEmptyPassphrase  = errors.New("keystore: empty passphrase")
+)
+
+// file is the on-disk layout.
+type file struct {
+	Version  int               `json:"version"`
+	KDF      kdf               `json:"kdf"`
+	Cipher   string            `json:"cipher"`
+	Accounts map[string]*entry `json:"accounts"`
+}
+
+type kdf struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+type entry struct {
+	PublicKey  string `json:"publicKey"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"` // the sealed 64-byte private key
+}
+
+// Keystore is an open keystore file. It is not safe for concurrent use.
+type Keystore struct {
+	path string
+	file file
+	aead cipher.AEAD // nil until unlocked
+}
+
+// Open reads the keystore at path, or starts an empty one if the file does not exist yet.
+// A leading ~/ is expanded to the home directory.
+func Open(path string) (*Keystore, error) {
+	path = pathutil.ExpandHome(path)
+	ks := &Keystore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		salt := make([]byte, saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		ks.file = file{
+			Version:  version,
+			KDF:      kdf{Name: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt},
+			Cipher:   cipherAESGCM,
+			Accounts: make(map[string]*entry),
+		}
+		return ks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	if err := json.Unmarshal(data, &ks.file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore %s: %w", path, err)
+	}
+	if ks.file.Version != version || ks.file.KDF.Name != "scrypt" || ks.file.Cipher != cipherAESGCM {
+		return nil, fmt.Errorf("%w: version %d, kdf %s, cipher %s", ErrUnsupportedStore, ks.file.Version, ks.file.KDF.Name, ks.file.Cipher)
+	}
+	if ks.file.Accounts == nil {
+		ks.file.Accounts = make(map[string]*entry)
+	}
+	return ks, nil
+}
+
+// OpenDefault opens the keystore named by SOLANA_STARTER_KEYSTORE, or DefaultPath.
+func OpenDefault() (*Keystore, error) {
+	path := os.Getenv(EnvPath)
+	if path == "" {
+		path = DefaultPath
+	}
+	return Open(path)
+}
+
+// Path returns the file the keystore is saved to.
+func (ks *Keystore) Path() string {
+	return ks.path
+}
+
+// Unlock derives the encryption key from passphrase. If the keystore holds accounts, the passphrase
+// is checked against one of them and ErrWrongPassphrase returned when it does not open it.
+// Otherwise the passphrase is the one the keystore is created with, and ErrEmptyPassphrase is returned if it is empty.
+func (ks *Keystore) Unlock(passphrase string) error {
+	if passphrase == "" && len(ks.Names()) == 0 {
+		return ErrEmptyPassphrase
+	}
+	k := ks.file.KDF
+	key, err := scrypt.Key([]byte(passphrase), k.Salt, k.N, k.R, k.P, keyLength)
+	if err != nil {
+		return fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	if names := ks.Names(); len(names) > 0 {
+		if _, err := open(aead, names[0], ks.file.Accounts[names[0]]); err != nil {
+			return err
+		}
+	}
+	ks.aead = aead
+	return nil
+}
+
+// Unlocked reports whether Unlock has succeeded.
+func (ks *Keystore) Unlocked() bool {
+	return ks.aead != nil
+}
+
+// Names returns the names of the stored accounts, sorted.
+func (ks *Keystore) Names() []string {
+	names := make([]string, 0, len(ks.file.Accounts))
+	for name := range ks.file.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether an account is stored under name.
+func (ks *Keystore) Has(name string) bool {
+	_, ok := ks.file.Accounts[name]
+	return ok
+}
+
+// PublicKey returns the public key stored under name, which does not need the keystore to be unlocked.
+func (ks *Keystore) PublicKey(name string) (common.PublicKey, error) {
+	e, ok := ks.file.Accounts[name]
+	if !ok {
+		return common.PublicKey{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return common.PublicKeyFromString(e.PublicKey), nil
+}
+
+// Get decrypts the account stored under name.
+func (ks *Keystore) Get(name string) (types.Account, error) {
+	e, ok := ks.file.Accounts[name]
+	if !ok {
+		return types.Account{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if ks.aead == nil {
+		return types.Account{}, ErrLocked
+	}
+	return open(ks.aead, name, e)
+}
+
+// Put encrypts account under name. It returns ErrExists if the name is taken; Remove it first to replace it.
+// Changes are kept in memory until Save.
+func (ks *Keystore) Put(name string, account types.Account) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if ks.Has(name) {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	if ks.aead == nil {
+		return ErrLocked
+	}
+	nonce := make([]byte, ks.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	publicKey := account.PublicKey.ToBase58()
+	ks.file.Accounts[name] = &entry{
+		PublicKey:  publicKey,
+		Nonce:      nonce,
+		Ciphertext: ks.aead.Seal(nil, nonce, account.PrivateKey, additionalData(name, publicKey)),
+	}
+	return nil
+}
+
+// Remove deletes the account stored under name. Changes are kept in memory until Save.
+func (ks *Keystore) Remove(name string) error {
+	if !ks.Has(name) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(ks.file.Accounts, name)
+	return nil
+}
+
+// Save writes the keystore to its path, readable only by the owner.
+// The file is replaced atomically so an interrupted save cannot lose accounts.
+func (ks *Keystore) Save() error {
+	data, err := json.MarshalIndent(ks.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ks.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ks.path), filepath.Base(ks.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save keystore: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save keystore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ks.path); err != nil {
+		return fmt.Errorf("failed to save keystore: %w", err)
+	}
+	return nil
+}
+
+var (
+	defaultMu sync.Mutex
+	// defaultStore is the default keystore once unlocked, so a command loading several signers asks once
+	defaultStore *Keystore
+)
+
+// Load opens the default keystore, unlocks it with Passphrase and decrypts the account stored under name.
+func Load(name string) (types.Account, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultStore != nil {
+		return defaultStore.Get(name)
+	}
+
+	ks, err := OpenDefault()
+	if err != nil {
+		return types.Account{}, err
+	}
+	if !ks.Has(name) {
+		return types.Account{}, fmt.Errorf("%w: %s in %s", ErrNotFound, name, ks.Path())
+	}
+	passphrase, err := Passphrase(fmt.Sprintf("passphrase for %s: ", ks.Path()))
+	if err != nil {
+		return types.Account{}, err
+	}
+	if err := ks.Unlock(passphrase); err != nil {
+		return types.Account{}, err
+	}
+	defaultStore = ks
+	return ks.Get(name)
+}
+
+// Address returns the public key of the account stored under name in the default keystore, or name itself
+// when there is no such account, so commands can take either a keystore name or an address.
+func Address(name string) string {
+	ks, err := OpenDefault()
+	if err != nil || !ks.Has(name) {
+		return name
+	}
+	publicKey, _ := ks.PublicKey(name)
+	return publicKey.ToBase58()
+}
+
+// Passphrase returns SOLANA_STARTER_KEYSTORE_PASSPHRASE if it is set, or else prints message to stderr
+// and reads a line from stdin, without echoing it when stdin is a terminal.
+func Passphrase(message string) (string, error) {
+	if passphrase, ok := os.LookupEnv(EnvPassphrase); ok {
+		return passphrase, nil
+	}
+	passphrase, err := prompt.Secret(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+func open(aead cipher.AEAD, name string, e *entry) (types.Account, error) {
+	// aead.Open panics on a nonce of the wrong length, which only an edited or corrupted file has
+	if len(e.Nonce) != aead.NonceSize() {
+		return types.Account{}, fmt.Errorf("%w: %s", ErrWrongPassphrase, name)
+	}
+	key, err := aead.Open(nil, e.Nonce, e.Ciphertext, additionalData(name, e.PublicKey))
+	if err != nil {
+		return types.Account{}, ErrWrongPassphrase
+	}
+	account, err := types.AccountFromBytes(key)
+	if err != nil || account.PublicKey.ToBase58() != e.PublicKey {
+		return types.Account{}, fmt.Errorf("%w: %s", ErrWrongPassphrase, name)
+	}
+	return account, nil
+}
+
+// additionalData binds a sealed key to its entry, so ciphertexts cannot be swapped between names.
+func additionalData(name, publicKey string) []byte {
+	return []byte(name + "\x00" + publicKey)
+}
+
+// validateName allows names that cannot be mistaken for a file path or signer URI.
+func validateName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\:?. `) {
+		return fmt.Errorf("%w: %q, use letters, digits, - and _", ErrInvalidName, name)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+const passphrase = "correct horse battery staple"
+
+// newKeystore saves a keystore holding alice and bob in a temporary directory and returns its path with the accounts.
+func newKeystore(t *testing.T) (string, map[string]types.Account) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	accounts := map[string]types.Account{"alice": types.NewAccount(), "bob": types.NewAccount()}
+	for name, account := range accounts {
+		if err := ks.Put(name, account); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ks.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path, accounts
+}
+
+func TestRoundTrip(t *testing.T) {
+	path, accounts := newKeystore(t)
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("keystore file: %v, mode %v", err, info.Mode())
+	}
+
+	ks, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, account := range accounts {
+		// Public keys are readable while locked, private keys are not
+		publicKey, err := ks.PublicKey(name)
+		if err != nil || publicKey != account.PublicKey {
+			t.Errorf("public key of %s: got %v, %v", name, publicKey.ToBase58(), err)
+		}
+		if _, err := ks.Get(name); !errors.Is(err, ErrLocked) {
+			t.Errorf("got %v getting %s from a locked keystore, want %v", err, name, ErrLocked)
+		}
+	}
+
+	if err := ks.Unlock(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	for name, account := range accounts {
+		got, err := ks.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PublicKey != account.PublicKey || string(got.PrivateKey) != string(account.PrivateKey) {
+			t.Errorf("%s did not round-trip", name)
+		}
+	}
+	if err := ks.Put("alice", types.NewAccount()); !errors.Is(err, ErrExists) {
+		t.Errorf("got %v replacing alice, want %v", err, ErrExists)
+	}
+	if _, err := ks.Get("carol"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v getting a missing account, want %v", err, ErrNotFound)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	path, _ := newKeystore(t)
+	ks, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("got %v, want %v", err, ErrWrongPassphrase)
+	}
+	if ks.Unlocked() {
+		t.Error("unlocked with the wrong passphrase")
+	}
+
+	empty, err := Open(filepath.Join(t.TempDir(), "keystore.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := empty.Unlock(""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("got %v creating a keystore with an empty passphrase, want %v", err, ErrEmptyPassphrase)
+	}
+}
+
+func TestTamperedEntry(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(f *file)
+	}{
+		{"ciphertext", func(f *file) { f.Accounts["bob"].Ciphertext[0] ^= 1 }},
+		{"public key", func(f *file) { f.Accounts["bob"].PublicKey = f.Accounts["alice"].PublicKey }},
+		{"swapped entries", func(f *file) { f.Accounts["alice"], f.Accounts["bob"] = f.Accounts["bob"], f.Accounts["alice"] }},
+		{"short nonce", func(f *file) { f.Accounts["bob"].Nonce = f.Accounts["bob"].Nonce[:4] }},
+		{"missing nonce", func(f *file) { f.Accounts["alice"].Nonce = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := newKeystore(t)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var f file
+			if err := json.Unmarshal(data, &f); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(&f)
+			if data, err = json.Marshal(f); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			ks, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Unlock checks alice, so a tampered bob only shows when he is read
+			err = ks.Unlock(passphrase)
+			if err == nil {
+				_, err = ks.Get("bob")
+			}
+			if !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("got %v, want %v", err, ErrWrongPassphrase)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package output writes decoded transfers as text, pretty JSON, newline-delimited JSON or CSV.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"solana-starter/pkg/decoder"
+)
+
+// Format is an output encoding.
+type Format string
+
+const (
+	FormatText   Format = "text"   // one Go-syntax line per transfer, as the examples have always printed
+	FormatJSON   Format = "json"   // a single indented JSON array
+	FormatNDJSON Format = "ndjson" // one compact JSON object per line
+	FormatCSV    Format = "csv"    // a header row followed by one row per transfer, in Columns order
+)
+
+// Formats lists the supported formats, for flag help.
+var Formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatCSV}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, expected one of %v", s, Formats)
+}
+
+// Record is a transfer with the transaction it came from. The transaction fields are optional
+// and left out of JSON when zero.
+type Record struct {
+	Signature string `json:"signature,omitempty"`
+	Slot      uint64 `json:"slot,omitempty"`
+	BlockTime *int64 `json:"blockTime,omitempty"`
+	*decoder.Transfer
+}
+
+// Columns is the CSV header. The order is part of the format: new columns are only ever appended.
+var Columns = []string{
+	"signature",
+	"slot",
+	"blockTime",
+	"type",
+	"tokenAddress",
+	"decimals",
+	"symbol",
+	"name",
+	"authority",
+	"source",
+	"sourceOwner",
+	"destination",
+	"destinationOwner",
+	"amount",
+	"uiAmount",
+	"fee",
+	"uiFee",
+	"programID",
+	"isInnerInstruction",
+	"outerInstructionIndex",
+	"outerInstructionProgramID",
+	"outerInstructionProgramName",
+}
+
+// Writer encodes records one at a time.
+type Writer interface {
+	Write(record Record) error
+	// Close finishes the output, e.g. the closing bracket of a JSON array, and closes the underlying file if there is one.
+	Close() error
+}
+
+// Create returns a Writer for path, or for stdout when path is empty or "-".
+func Create(path string, format Format) (Writer, error) {
+	if path == "" || path == "-" {
+		return NewWriter(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	w, err := NewWriter(f, format)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileWriter{Writer: w, f: f}, nil
+}
+
+// NewWriter returns a Writer that encodes to w. Closing it does not close w.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatText:
+		return &textWriter{w: w}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// WriteEnvelope writes the transfers of a decoded transaction, which are none if it failed.
+// Text output shows the transaction once, in a line of its own before its transfers;
+// the other formats repeat its signature, slot and block time on every record.
+func WriteEnvelope(w Writer, envelope *decoder.Envelope) error {
+	if tw, ok := w.(transactionWriter); ok {
+		return tw.writeTransaction(envelope)
+	}
+	return writeRecords(w, envelope)
+}
+
+// transactionWriter is implemented by writers that show a transaction other than as a record per transfer.
+type transactionWriter interface {
+	writeTransaction(envelope *decoder.Envelope) error
+}
+
+func writeRecords(w Writer, envelope *decoder.Envelope) error {
+	for _, transfer := range envelope.Transfers() {
+		record := Record{Signature: envelope.Signature, Slot: envelope.Slot, BlockTime: envelope.BlockTime, Transfer: transfer}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fileWriter struct {
+	Writer
+	f *os.File
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+func (w *fileWriter) writeTransaction(envelope *decoder.Envelope) error {
+	return WriteEnvelope(w.Writer, envelope)
+}
+
+type textWriter struct {
+	w io.Writer
+}
+
+func (w *textWriter) Write(record Record) error {
+	var err error
+	if record.Slot == 0 {
+		_, err = fmt.Fprintf(w.w, "Transfer: %+v\n", *record.Transfer)
+	} else {
+		_, err = fmt.Fprintf(w.w, "slot %d tx %s: %+v\n", record.Slot, record.Signature, *record.Transfer)
+	}
+	return err
+}
+
+func (w *textWriter) writeTransaction(envelope *decoder.Envelope) error {
+	var computeUnits uint64
+	if envelope.ComputeUnitsConsumed != nil {
+		computeUnits = *envelope.ComputeUnitsConsumed
+	}
+	_, err := fmt.Fprintf(w.w, "Transaction: slot %d, fee payer %s, fee %d lamports, %d compute units, %s\n",
+		envelope.Slot, envelope.FeePayer, envelope.Fee, computeUnits, envelope.Status)
+	if err != nil {
+		return err
+	}
+	// The transaction is already shown, so transfers are written without it
+	for _, transfer := range envelope.Transfers() {
+		if err := w.Write(Record{Transfer: transfer}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *textWriter) Close() error { return nil }
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonWriter) Write(record Record) error {
+	data, err := json.MarshalIndent(record, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if w.count == 0 {
+		sep = "[\n  "
+	}
+	w.count++
+	_, err = fmt.Fprintf(w.w, "%s%s", sep, data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	// An empty result is still a valid array
+	closing := "\n]\n"
+	if w.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(w.w, closing)
+	return err
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(record Record) error {
+	return w.enc.Encode(record)
+}
+
+func (w *ndjsonWriter) Close() error { return nil }
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(record Record) error {
+	if !w.headerWritten {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	if err := w.w.Write(row(record)); err != nil {
+		return err
+	}
+	// Flush per row so a long-running scan can be tailed
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	if !w.headerWritten {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// row returns the CSV fields of a record in Columns order.
+func row(record Record) []string {
+	var slot, blockTime string
+	if record.Slot != 0 {
+		slot = strconv.FormatUint(record.Slot, 10)
+	}
+	if record.BlockTime != nil {
+		blockTime = strconv.FormatInt(*record.BlockTime, 10)
+	}
+	t := record.Transfer
+	return []string{
+		record.Signature,
+		slot,
+		blockTime,
+		t.Type,
+		t.TokenAddress,
+		strconv.Itoa(int(t.Decimals)),
+		t.Symbol,
+		t.Name,
+		t.Authority,
+		t.Source,
+		t.SourceOwner,
+		t.Destination,
+		t.DestinationOwner,
+		t.Amount,
+		t.UiAmount,
+		t.Fee,
+		t.UiFee,
+		t.ProgramID,
+		strconv.FormatBool(t.IsInnerInstruction),
+		strconv.Itoa(t.OuterInstructionIndex),
+		t.OuterInstructionProgramID,
+		t.OuterInstructionProgramName,
+	}
+}
@@ -0,0 +1,55 @@
+// Package prompt reads answers from stdin one line at a time, so several prompts can share piped input.
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReadLine reads one line from r and returns it without the line ending.
+// It reads byte by byte, so input meant for a later read is left in r. A last line without a newline
+// is returned as is; io.EOF is only returned when there is nothing left to read.
+func ReadLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) {
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
+
+// Secret prints prompt to stderr and reads a line from stdin, without echoing it when stdin is a terminal.
+func Secret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return ReadLine(os.Stdin)
+	}
+	secret, err := term.ReadPassword(fd)
+	// The newline typed was not echoed either
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
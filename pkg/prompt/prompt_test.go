@@ -0,0 +1,24 @@
+package prompt
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadLineLeavesTheRest(t *testing.T) {
+	r := strings.NewReader("base58key\r\npassphrase\nlast")
+	for _, want := range []string{"base58key", "passphrase", "last"} {
+		got, err := ReadLine(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	if _, err := ReadLine(r); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v at the end of input, want io.EOF", err)
+	}
+}
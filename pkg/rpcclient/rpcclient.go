@@ -0,0 +1,408 @@
+// Package rpcclient builds a *client.Client that survives the failures public RPC endpoints are known for.
+//
+// Requests go through a Transport that waits on a token bucket per endpoint, retries rate limits, timeouts
+// and retryable JSON-RPC errors with jittered exponential backoff, and fails over to the next endpoint in
+// the list. An endpoint that fails is taken out of rotation until a getHealth check passes again.
+package rpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+)
+
+// Defaults used unless the corresponding Option is given.
+const (
+	DefaultMaxAttempts         = 5
+	DefaultBaseDelay           = 250 * time.Millisecond
+	DefaultMaxDelay            = 10 * time.Second
+	DefaultAttemptTimeout      = 30 * time.Second
+	DefaultHealthCheckInterval = 30 * time.Second
+)
+
+// The public cluster endpoints allow 100 requests per 10 seconds per IP, and 40 for a single method.
+// They are limited to PublicRateLimit requests per second unless WithRateLimit is given.
+const (
+	PublicRateLimit = 4
+	PublicBurst     = 8
+)
+
+// healthCheckTimeout bounds a getHealth probe, so a dead endpoint does not hold up failover.
+const healthCheckTimeout = 5 * time.Second
+
+// JSON-RPC error codes worth retrying: the node is behind or briefly lacks the data asked for.
+const (
+	errCodeInternal                  = -32603
+	errCodeBlockNotAvailable         = -32004
+	errCodeNodeUnhealthy             = -32005
+	errCodeBlockStatusNotAvailable   = -32014
+	errCodeMinContextSlotNotReached  = -32016
+	errCodeRateLimited               = 429 // sent in the body by some providers
+	errCodeTransactionHistoryMissing = -32011
+)
+
+// ErrNoEndpoints is returned by NewTransport when the endpoint list is empty.
+var ErrNoEndpoints = errors.New("rpcclient: no endpoints")
+
+// Transport is an http.RoundTripper sending JSON-RPC requests to a list of endpoints.
+// The URL of the request is ignored; the first healthy endpoint in the list is used instead.
+// It is safe for concurrent use.
+type Transport struct {
+	base                http.RoundTripper
+	endpoints           []*endpoint
+	maxAttempts         int
+	baseDelay           time.Duration
+	maxDelay            time.Duration
+	attemptTimeout      time.Duration
+	healthCheckInterval time.Duration
+	rate                float64 // requests per second per endpoint, 0 for the defaults
+	burst               int
+}
+
+// Option configures a Transport.
+type Option func(*Transport)
+
+// WithMaxAttempts sets how many times a request is sent before its last failure is returned.
+func WithMaxAttempts(n int) Option {
+	return func(t *Transport) {
+		if n > 0 {
+			t.maxAttempts = n
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled on every further retry up to max.
+// Each delay is drawn at random below the current bound, so clients that failed together spread out.
+func WithBackoff(base, max time.Duration) Option {
+	return func(t *Transport) {
+		if base > 0 && max >= base {
+			t.baseDelay, t.maxDelay = base, max
+		}
+	}
+}
+
+// WithAttemptTimeout bounds a single request; one that times out is retried, on another endpoint if there is one.
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(t *Transport) {
+		if d > 0 {
+			t.attemptTimeout = d
+		}
+	}
+}
+
+// WithHealthCheckInterval sets how long a failed endpoint is skipped before getHealth is asked whether it is back.
+func WithHealthCheckInterval(d time.Duration) Option {
+	return func(t *Transport) {
+		if d > 0 {
+			t.healthCheckInterval = d
+		}
+	}
+}
+
+// WithRateLimit allows each endpoint perSecond requests per second on average and burst at once,
+// instead of PublicRateLimit for the public cluster endpoints and no limit for others.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(t *Transport) {
+		if perSecond > 0 {
+			t.rate, t.burst = perSecond, max(burst, 1)
+		}
+	}
+}
+
+// WithBaseTransport sets the transport requests are sent with, http.DefaultTransport by default.
+func WithBaseTransport(base http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.base = base
+	}
+}
+
+// New returns a client sending its requests through a Transport over endpoints, tried in order.
+func New(endpoints []string, opts ...Option) (*client.Client, error) {
+	t, err := NewTransport(endpoints, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(
+		rpc.WithEndpoint(endpoints[0]),
+		rpc.WithHTTPClient(&http.Client{Transport: t}),
+	), nil
+}
+
+// NewTransport returns a Transport over endpoints. The first is preferred; the others are fallbacks.
+func NewTransport(endpoints []string, opts ...Option) (*Transport, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	t := &Transport{
+		base:                http.DefaultTransport,
+		maxAttempts:         DefaultMaxAttempts,
+		baseDelay:           DefaultBaseDelay,
+		maxDelay:            DefaultMaxDelay,
+		attemptTimeout:      DefaultAttemptTimeout,
+		healthCheckInterval: DefaultHealthCheckInterval,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	for _, raw := range endpoints {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("rpcclient: invalid endpoint %q", raw)
+		}
+		rate, burst := t.rate, t.burst
+		if rate == 0 && isPublicEndpoint(raw) {
+			rate, burst = PublicRateLimit, PublicBurst
+		}
+		t.endpoints = append(t.endpoints, &endpoint{url: u, limiter: newLimiter(rate, burst)})
+	}
+	return t, nil
+}
+
+func isPublicEndpoint(endpoint string) bool {
+	switch endpoint {
+	case rpc.DevnetRPCEndpoint, rpc.TestnetRPCEndpoint, rpc.MainnetRPCEndpoint:
+		return true
+	}
+	return false
+}
+
+// endpoint is an RPC URL with its rate limit and health.
+type endpoint struct {
+	url     *url.URL
+	limiter *limiter
+
+	mu sync.Mutex
+	// unhealthy is set when a request fails for a reason of the node's own; checkAfter is when to probe it again
+	unhealthy  bool
+	checkAfter time.Time
+	// throttledUntil is when the endpoint stops rate limiting us or asking us to wait
+	throttledUntil time.Time
+}
+
+// attempt is the outcome of sending a request to one endpoint.
+type attempt struct {
+	res        *http.Response // nil if the request failed before a response
+	err        error
+	retry      bool
+	throttled  bool          // skip the endpoint for a while, see endpoint.fail
+	unhealthy  bool          // take the endpoint out of rotation
+	retryAfter time.Duration // how long the endpoint asked us to wait, if it did
+}
+
+// RoundTrip sends req to the first available endpoint and retries it, on the next endpoint when there is one,
+// until it succeeds, fails for a reason retrying cannot fix, or runs out of attempts.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx := req.Context()
+
+	var last attempt
+	for i := 0; i < t.maxAttempts; i++ {
+		if i > 0 {
+			if err := sleep(ctx, min(max(t.backoff(i), last.retryAfter), t.maxDelay)); err != nil {
+				return nil, err
+			}
+		}
+		e := t.pick(ctx)
+		if err := e.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		last = t.send(ctx, e, req, body)
+		if !last.retry {
+			return last.res, last.err
+		}
+		e.fail(last, t.healthCheckInterval)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	if last.res != nil {
+		// The SDK reports the status code and body of the final response
+		return last.res, nil
+	}
+	return nil, fmt.Errorf("rpcclient: %d attempts failed: %w", t.maxAttempts, last.err)
+}
+
+// send makes one attempt at req against e and classifies the outcome.
+func (t *Transport) send(ctx context.Context, e *endpoint, req *http.Request, body []byte) attempt {
+	ctx, cancel := context.WithTimeout(ctx, t.attemptTimeout)
+	defer cancel()
+	r := req.Clone(ctx)
+	r.URL = e.url
+	r.Host = ""
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	res, err := t.base.RoundTrip(r)
+	if err != nil {
+		// Timeouts and refused connections alike: the endpoint is down or overloaded
+		return attempt{err: err, retry: req.Context().Err() == nil, unhealthy: true}
+	}
+	// Read the body while the attempt's context is alive, to look for JSON-RPC errors and to retry safely
+	data, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return attempt{err: err, retry: req.Context().Err() == nil, unhealthy: true}
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	retryAfter := parseRetryAfter(res.Header.Get("Retry-After"))
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return attempt{res: res, retry: true, throttled: true, retryAfter: retryAfter}
+	case res.StatusCode == http.StatusBadGateway || res.StatusCode == http.StatusServiceUnavailable ||
+		res.StatusCode == http.StatusGatewayTimeout || res.StatusCode == http.StatusInternalServerError:
+		return attempt{res: res, retry: true, unhealthy: true}
+	case res.StatusCode != http.StatusOK:
+		return attempt{res: res}
+	}
+
+	var reply struct {
+		Error *rpc.JsonRpcError `json:"error"`
+	}
+	if json.Unmarshal(data, &reply) != nil || reply.Error == nil {
+		return attempt{res: res}
+	}
+	switch reply.Error.Code {
+	case errCodeRateLimited:
+		return attempt{res: res, retry: true, throttled: true, retryAfter: retryAfter}
+	case errCodeNodeUnhealthy:
+		// The node is behind: wait for it, and for its health check to pass, on another endpoint
+		return attempt{res: res, retry: true, throttled: true, unhealthy: true, retryAfter: retryAfter}
+	case errCodeBlockNotAvailable, errCodeBlockStatusNotAvailable, errCodeMinContextSlotNotReached,
+		errCodeTransactionHistoryMissing, errCodeInternal:
+		// Another node, or the same one a little later, may have caught up
+		return attempt{res: res, retry: true}
+	}
+	return attempt{res: res}
+}
+
+// pick returns the first endpoint in the list that is healthy and not throttled, probing failed endpoints
+// whose check is due. If none is available it returns the one whose throttling ends first, or the first one.
+func (t *Transport) pick(ctx context.Context) *endpoint {
+	var soonest *endpoint
+	var soonestUntil time.Time
+	for _, e := range t.endpoints {
+		now := time.Now()
+		e.mu.Lock()
+		unhealthy, probe := e.unhealthy, e.unhealthy && !now.Before(e.checkAfter)
+		if probe {
+			// Claim the check, so concurrent requests skip the endpoint instead of probing it too
+			e.checkAfter = now.Add(t.healthCheckInterval)
+		}
+		throttledUntil := e.throttledUntil
+		e.mu.Unlock()
+
+		if unhealthy && !(probe && t.probe(ctx, e)) {
+			continue
+		}
+		if now.Before(throttledUntil) {
+			if soonest == nil || throttledUntil.Before(soonestUntil) {
+				soonest, soonestUntil = e, throttledUntil
+			}
+			continue
+		}
+		return e
+	}
+	if soonest != nil {
+		return soonest
+	}
+	return t.endpoints[0]
+}
+
+// probe asks e whether it is healthy with getHealth, and returns it to rotation if so.
+func (t *Transport) probe(ctx context.Context, e *endpoint) bool {
+	healthy := t.getHealth(ctx, e)
+	if healthy {
+		e.mu.Lock()
+		e.unhealthy = false
+		e.mu.Unlock()
+	}
+	return healthy
+}
+
+func (t *Transport) getHealth(ctx context.Context, e *endpoint) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if err := e.limiter.wait(ctx); err != nil {
+		return false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url.String(),
+		bytes.NewReader([]byte(`{"jsonrpc":"2.0","id":1,"method":"getHealth"}`)))
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+	var reply struct {
+		Result string `json:"result"`
+	}
+	return res.StatusCode == http.StatusOK && json.NewDecoder(res.Body).Decode(&reply) == nil && reply.Result == "ok"
+}
+
+// fail records a failed attempt: a throttled endpoint, whether by HTTP 429 or a JSON-RPC 429 or -32005 error,
+// is skipped for as long as it asked or else a second, and an unhealthy one until its health check passes.
+func (e *endpoint) fail(a attempt, healthCheckInterval time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	if a.throttled {
+		e.throttledUntil = now.Add(max(a.retryAfter, time.Second))
+	}
+	if a.unhealthy {
+		e.unhealthy = true
+		e.checkAfter = now.Add(healthCheckInterval)
+	}
+}
+
+// backoff returns the jittered delay before retry i, counting from 1.
+func (t *Transport) backoff(i int) time.Duration {
+	bound := t.maxDelay
+	if shift := i - 1; shift < 32 && t.baseDelay<<shift < t.maxDelay {
+		bound = t.baseDelay << shift
+	}
+	return rand.N(bound) + 1
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds; HTTP dates are not used by RPC providers.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,253 @@
+package rpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+)
+
+// node is a stub RPC endpoint. reply answers the nth request (from 0) of a method other than getHealth;
+// getHealth is answered "ok" unless sick is set.
+type node struct {
+	*httptest.Server
+	reply func(w http.ResponseWriter, n int)
+
+	mu     sync.Mutex
+	calls  int
+	health int
+	sick   bool
+}
+
+func newNode(t *testing.T, reply func(w http.ResponseWriter, n int)) *node {
+	t.Helper()
+	nd := &node{reply: reply}
+	nd.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		nd.mu.Lock()
+		if req.Method == "getHealth" {
+			nd.health++
+			sick := nd.sick
+			nd.mu.Unlock()
+			if sick {
+				rpcError(w, errCodeNodeUnhealthy)
+				return
+			}
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"ok"}`)
+			return
+		}
+		n := nd.calls
+		nd.calls++
+		nd.mu.Unlock()
+		nd.reply(w, n)
+	}))
+	t.Cleanup(nd.Close)
+	return nd
+}
+
+func (nd *node) counts() (calls, health int) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	return nd.calls, nd.health
+}
+
+func (nd *node) setSick(sick bool) {
+	nd.mu.Lock()
+	nd.sick = sick
+	nd.mu.Unlock()
+}
+
+func slot(w http.ResponseWriter) {
+	fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":42}`)
+}
+
+func rpcError(w http.ResponseWriter, code int) {
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"error":{"code":%d,"message":"stub error"}}`, code)
+}
+
+// newClient returns a client over the nodes, in order, with a short backoff unless opts set another.
+func newClient(t *testing.T, nodes []*node, opts ...Option) *client.Client {
+	t.Helper()
+	var endpoints []string
+	for _, nd := range nodes {
+		endpoints = append(endpoints, nd.URL)
+	}
+	c, err := New(endpoints, append([]Option{WithBackoff(time.Millisecond, 10*time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// getSlot sends a getSlot request with c, failing the test unless it returns 42.
+func getSlot(t *testing.T, c *client.Client) {
+	t.Helper()
+	got, err := c.GetSlot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 42 {
+		t.Fatalf("got slot %d, want 42", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	nd := newNode(t, func(w http.ResponseWriter, n int) {
+		if n == 0 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		slot(w)
+	})
+
+	start := time.Now()
+	getSlot(t, newClient(t, []*node{nd}, WithBackoff(time.Millisecond, 5*time.Second)))
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, before the second the endpoint asked for", elapsed)
+	}
+	if calls, _ := nd.counts(); calls != 2 {
+		t.Errorf("got %d requests, want 2", calls)
+	}
+}
+
+func TestThrottledFailover(t *testing.T) {
+	tests := []struct {
+		name     string
+		throttle func(w http.ResponseWriter)
+	}{
+		{"HTTP 429", func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}},
+		{"JSON-RPC 429", func(w http.ResponseWriter) { rpcError(w, errCodeRateLimited) }},
+		{"JSON-RPC -32005", func(w http.ResponseWriter) { rpcError(w, errCodeNodeUnhealthy) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := newNode(t, func(w http.ResponseWriter, n int) { tt.throttle(w) })
+			fallback := newNode(t, func(w http.ResponseWriter, n int) { slot(w) })
+			c := newClient(t, []*node{primary, fallback})
+
+			getSlot(t, c)
+			// The primary is still throttled, so the next request goes straight to the fallback
+			getSlot(t, c)
+			if calls, _ := primary.counts(); calls != 1 {
+				t.Errorf("primary got %d requests, want 1", calls)
+			}
+			if calls, _ := fallback.counts(); calls != 2 {
+				t.Errorf("fallback got %d requests, want 2", calls)
+			}
+		})
+	}
+}
+
+func TestServerErrorFailover(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			primary := newNode(t, func(w http.ResponseWriter, n int) { w.WriteHeader(status) })
+			fallback := newNode(t, func(w http.ResponseWriter, n int) { slot(w) })
+
+			getSlot(t, newClient(t, []*node{primary, fallback}))
+			if calls, _ := primary.counts(); calls != 1 {
+				t.Errorf("primary got %d requests, want 1", calls)
+			}
+			if calls, _ := fallback.counts(); calls != 1 {
+				t.Errorf("fallback got %d requests, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestServerErrorAttempts(t *testing.T) {
+	nd := newNode(t, func(w http.ResponseWriter, n int) { w.WriteHeader(http.StatusServiceUnavailable) })
+	c := newClient(t, []*node{nd}, WithMaxAttempts(3))
+	if _, err := c.GetSlot(context.Background()); err == nil {
+		t.Fatal("got no error from an endpoint answering 503")
+	}
+	if calls, _ := nd.counts(); calls != 3 {
+		t.Errorf("got %d requests, want 3", calls)
+	}
+}
+
+func TestUnhealthyRecovery(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	primary := newNode(t, func(w http.ResponseWriter, n int) {
+		if n == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		slot(w)
+	})
+	fallback := newNode(t, func(w http.ResponseWriter, n int) { slot(w) })
+	c := newClient(t, []*node{primary, fallback}, WithHealthCheckInterval(interval))
+	send := func() {
+		t.Helper()
+		getSlot(t, c)
+	}
+
+	// The 503 takes the primary out of rotation until its health check is due
+	primary.setSick(true)
+	send()
+	send()
+	if calls, health := primary.counts(); calls != 1 || health != 0 {
+		t.Fatalf("primary got %d requests and %d health checks, want 1 and 0", calls, health)
+	}
+
+	// A failed check keeps it out for another interval
+	time.Sleep(interval + 10*time.Millisecond)
+	send()
+	if calls, health := primary.counts(); calls != 1 || health != 1 {
+		t.Fatalf("primary got %d requests and %d health checks, want 1 and 1", calls, health)
+	}
+
+	// Once a check passes it is preferred again
+	primary.setSick(false)
+	time.Sleep(interval + 10*time.Millisecond)
+	send()
+	send()
+	if calls, health := primary.counts(); calls != 3 || health != 2 {
+		t.Errorf("primary got %d requests and %d health checks, want 3 and 2", calls, health)
+	}
+	if calls, _ := fallback.counts(); calls != 3 {
+		t.Errorf("fallback got %d requests, want 3", calls)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	const rate = 100
+	l := newLimiter(rate, 2)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst of 2 is free; the other 3 wait a hundredth of a second each
+	if elapsed := time.Since(start); elapsed < 3*time.Second/rate-5*time.Millisecond {
+		t.Errorf("5 requests took %v, want at least %v", elapsed, 3*time.Second/rate)
+	}
+
+	// A waiter that gives up returns the context's error
+	l = newLimiter(1, 1)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("got no error waiting with a canceled context")
+	}
+}
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Checkpoint stores the last slot a scan completed.
+type Checkpoint interface {
+	// Load returns the saved slot; ok is false when nothing was saved yet.
+	Load() (slot uint64, ok bool, err error)
+	// Save records that every block up to and including slot has been sent.
+	Save(slot uint64) error
+}
+
+// FileCheckpoint keeps the checkpoint slot as text in a file.
+type FileCheckpoint string
+
+// Load reads the slot from the file. A missing file means no checkpoint.
+func (f FileCheckpoint) Load() (uint64, bool, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	slot, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid checkpoint %s: %w", string(f), err)
+	}
+	return slot, true, nil
+}
+
+// Save writes slot to the file, replacing it atomically.
+func (f FileCheckpoint) Save(slot uint64) error {
+	tmp := string(f) + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(slot, 10)+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, string(f)); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
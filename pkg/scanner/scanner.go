@@ -0,0 +1,254 @@
+// Package scanner extracts every transfer in a range of slots by fetching whole blocks
+// and running them through the decoder.
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/mr-tron/base58"
+
+	"solana-starter/pkg/decoder"
+)
+
+// DefaultConcurrency is the number of blocks fetched in parallel unless WithConcurrency is given.
+const DefaultConcurrency = 4
+
+// maxGetBlocksRange is the widest slot range getBlocks accepts.
+const maxGetBlocksRange = 500_000
+
+// JSON-RPC error codes for slots that hold no block.
+const (
+	errCodeSlotSkipped             = -32007
+	errCodeLongTermStorageSlotSkip = -32009
+)
+
+// Record is a transfer found by the scanner, with the transaction that made it.
+type Record struct {
+	Slot      uint64
+	Signature string
+	Index     int // position of the transaction in its block
+	Transfer  *decoder.Transfer
+}
+
+// Scanner walks a slot range block by block.
+type Scanner struct {
+	c           *client.Client
+	d           *decoder.Decoder
+	concurrency int
+	checkpoint  Checkpoint
+}
+
+// Option configures a Scanner.
+type Option func(*Scanner)
+
+// WithConcurrency sets how many blocks are fetched and decoded at once.
+func WithConcurrency(n int) Option {
+	return func(s *Scanner) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithCheckpoint makes the Scanner resume after the slot saved in cp and save each slot it completes.
+func WithCheckpoint(cp Checkpoint) Option {
+	return func(s *Scanner) {
+		s.checkpoint = cp
+	}
+}
+
+// New returns a Scanner that fetches blocks with c and decodes them with d.
+func New(c *client.Client, d *decoder.Decoder, opts ...Option) *Scanner {
+	s := &Scanner{
+		c:           c,
+		d:           d,
+		concurrency: DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type blockResult struct {
+	slot    uint64
+	records []Record
+	err     error
+}
+
+// Scan sends the transfers of every block from startSlot to endSlot, inclusive, to out.
+// Blocks are fetched concurrently but records arrive in slot order and, within a block, in transaction order.
+// Skipped slots are passed over, and failed transactions are left out because they moved nothing.
+// With a checkpoint, the scan starts after the saved slot and saves every slot once its records are sent.
+// Scan returns when the range is done, the context is cancelled or a block cannot be fetched; it does not close out.
+func (s *Scanner) Scan(ctx context.Context, startSlot, endSlot uint64, out chan<- Record) error {
+	if s.checkpoint != nil {
+		last, ok, err := s.checkpoint.Load()
+		if err != nil {
+			return err
+		}
+		if ok && last+1 > startSlot {
+			startSlot = last + 1
+		}
+	}
+	if startSlot > endSlot {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Results are queued in slot order; the buffer bounds how far fetching runs ahead of the consumer,
+	// and fetching holds one of concurrency slots until its block is decoded
+	results := make(chan chan blockResult, s.concurrency)
+	fetching := make(chan struct{}, s.concurrency)
+	var listErr error
+	go func() {
+		defer close(results)
+		listErr = s.eachBlock(ctx, startSlot, endSlot, func(slot uint64) bool {
+			result := make(chan blockResult, 1)
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case fetching <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+			go func() {
+				defer func() { <-fetching }()
+				result <- s.scanBlock(ctx, slot)
+			}()
+			return true
+		})
+	}()
+
+	for result := range results {
+		var block blockResult
+		select {
+		case block = <-result:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if block.err != nil {
+			return block.err
+		}
+		for _, record := range block.records {
+			select {
+			case out <- record:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if s.checkpoint != nil {
+			if err := s.checkpoint.Save(block.slot); err != nil {
+				return err
+			}
+		}
+	}
+	if listErr != nil {
+		return listErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Slots skipped at the end of the range are done too
+	if s.checkpoint != nil {
+		return s.checkpoint.Save(endSlot)
+	}
+	return nil
+}
+
+// eachBlock calls fn for every slot in the range that has a block, stopping early when fn returns false.
+func (s *Scanner) eachBlock(ctx context.Context, startSlot, endSlot uint64, fn func(slot uint64) bool) error {
+	for from := startSlot; from <= endSlot; from += maxGetBlocksRange {
+		to := min(from+maxGetBlocksRange-1, endSlot)
+		res, err := s.c.RpcClient.GetBlocks(ctx, from, to)
+		if err == nil && res.Error != nil {
+			err = res.Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list blocks %d-%d: %w", from, to, err)
+		}
+		for _, slot := range res.Result {
+			if !fn(slot) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// scanBlock fetches one block with full transaction details and decodes its successful transactions.
+func (s *Scanner) scanBlock(ctx context.Context, slot uint64) blockResult {
+	block, err := s.c.GetBlockWithConfig(ctx, slot, client.GetBlockConfig{
+		TransactionDetails: rpc.GetBlockConfigTransactionDetailsFull,
+	})
+	if isSkippedSlot(err) || (err == nil && block == nil) {
+		return blockResult{slot: slot}
+	}
+	if err != nil {
+		return blockResult{slot: slot, err: fmt.Errorf("failed to get block %d: %w", slot, err)}
+	}
+
+	var blockTime *int64
+	if block.BlockTime != nil {
+		t := block.BlockTime.Unix()
+		blockTime = &t
+	}
+
+	var txs []*client.Transaction
+	var indexes []int
+	for i, blockTx := range block.Transactions {
+		if blockTx.Meta == nil || blockTx.Meta.Err != nil {
+			continue
+		}
+		txs = append(txs, &client.Transaction{
+			Slot:        slot,
+			Meta:        blockTx.Meta,
+			Transaction: blockTx.Transaction,
+			BlockTime:   blockTime,
+			AccountKeys: blockTx.AccountKeys,
+		})
+		indexes = append(indexes, i)
+	}
+
+	eventsByTx, err := s.d.DecodeTransactionsEvents(ctx, txs)
+	if err != nil {
+		return blockResult{slot: slot, err: fmt.Errorf("failed to decode block %d: %w", slot, err)}
+	}
+
+	var records []Record
+	for i, events := range eventsByTx {
+		var signature string
+		if len(txs[i].Transaction.Signatures) > 0 {
+			signature = base58.Encode(txs[i].Transaction.Signatures[0])
+		}
+		for _, event := range events {
+			if transfer, ok := event.(*decoder.Transfer); ok {
+				records = append(records, Record{
+					Slot:      slot,
+					Signature: signature,
+					Index:     indexes[i],
+					Transfer:  transfer,
+				})
+			}
+		}
+	}
+	return blockResult{slot: slot, records: records}
+}
+
+// isSkippedSlot reports whether err says the slot has no block, which getBlocks can race with.
+func isSkippedSlot(err error) bool {
+	var rpcErr *rpc.JsonRpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	return rpcErr.Code == errCodeSlotSkipped || rpcErr.Code == errCodeLongTermStorageSlotSkip
+}
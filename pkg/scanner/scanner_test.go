@@ -0,0 +1,322 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/fixture"
+)
+
+// Devnet transactions captured in the decoder's fixtures: two token transfers, and a SOL transfer funding a new token account.
+const (
+	tokenTransferSignature = "4fSTSDTTuYa1XXAFxFenuY3SoZWUwCzpMq7kUiya1zW6uqqh6C76GFqTQ3wvegEbZhbPJyr33iDAbieQVWCtVXmf"
+	ataCreationSignature   = "4Dj8Xbs7L6z7pbNp5eGZXLmYZLwePPRVTfunjx2EWDc4nwtVYRq4YqduiFKXR23cGqmbF6LHoubGnKa7gCozstGF"
+
+	fixtureDir = "../decoder/testdata"
+)
+
+// blockTx is a transaction as getBlock returns it.
+type blockTx struct {
+	Transaction json.RawMessage `json:"transaction"`
+	Meta        json.RawMessage `json:"meta"`
+	Version     any             `json:"version"`
+}
+
+// loadBlockTx reads a fixture as a block transaction, with err set in its meta if failed.
+func loadBlockTx(t *testing.T, signature string, failed bool) blockTx {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(fixtureDir, signature+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Result blockTx `json:"result"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if failed {
+		var meta map[string]any
+		if err := json.Unmarshal(res.Result.Meta, &meta); err != nil {
+			t.Fatal(err)
+		}
+		meta["err"] = map[string]any{"InstructionError": []any{0, "Custom"}}
+		if res.Result.Meta, err = json.Marshal(meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res.Result
+}
+
+// node is a stub RPC node serving getBlocks and getBlock from blocks.
+// A slot mapped to an error code is answered with that error; slow slots take delay to answer.
+type node struct {
+	*httptest.Server
+	blocks []uint64
+	txs    map[uint64][]blockTx
+	errors map[uint64]int
+	slow   map[uint64]bool
+	delay  time.Duration
+
+	mu          sync.Mutex
+	inflight    int
+	maxInflight int
+	getBlocks   [][2]uint64 // the ranges asked for
+}
+
+func newNode(t *testing.T, nd *node) *node {
+	t.Helper()
+	nd.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			return
+		}
+
+		res := rpc.JsonRpcResponse[any]{JsonRpc: "2.0", Id: req.ID}
+		switch req.Method {
+		case "getBlocks":
+			var from, to uint64
+			json.Unmarshal(req.Params[0], &from)
+			json.Unmarshal(req.Params[1], &to)
+			nd.mu.Lock()
+			nd.getBlocks = append(nd.getBlocks, [2]uint64{from, to})
+			nd.mu.Unlock()
+			slots := []uint64{}
+			for _, slot := range nd.blocks {
+				if slot >= from && slot <= to {
+					slots = append(slots, slot)
+				}
+			}
+			res.Result = slots
+		case "getBlock":
+			var slot uint64
+			json.Unmarshal(req.Params[0], &slot)
+			nd.mu.Lock()
+			nd.inflight++
+			nd.maxInflight = max(nd.maxInflight, nd.inflight)
+			nd.mu.Unlock()
+			if nd.slow[slot] {
+				time.Sleep(nd.delay)
+			} else {
+				time.Sleep(nd.delay / 10)
+			}
+			nd.mu.Lock()
+			nd.inflight--
+			nd.mu.Unlock()
+
+			if code, ok := nd.errors[slot]; ok {
+				res.Error = &rpc.JsonRpcError{Code: code, Message: "slot skipped"}
+				break
+			}
+			txs := nd.txs[slot]
+			if txs == nil {
+				txs = []blockTx{}
+			}
+			res.Result = map[string]any{
+				"blockhash":         "11111111111111111111111111111111",
+				"previousBlockhash": "11111111111111111111111111111111",
+				"parentSlot":        slot - 1,
+				"blockTime":         1_700_000_000 + slot,
+				"blockHeight":       slot,
+				"transactions":      txs,
+			}
+		default:
+			t.Errorf("unexpected %s request", req.Method)
+			http.Error(w, "unexpected method", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(nd.Close)
+	return nd
+}
+
+// memoryCheckpoint records every slot saved.
+type memoryCheckpoint struct {
+	saved []uint64
+}
+
+func (cp *memoryCheckpoint) Load() (uint64, bool, error) {
+	if len(cp.saved) == 0 {
+		return 0, false, nil
+	}
+	return cp.saved[len(cp.saved)-1], true, nil
+}
+
+func (cp *memoryCheckpoint) Save(slot uint64) error {
+	cp.saved = append(cp.saved, slot)
+	return nil
+}
+
+// scan runs a scan over the node and returns the records it sent.
+func scan(t *testing.T, nd *node, startSlot, endSlot uint64, opts ...Option) ([]Record, error) {
+	t.Helper()
+	c := client.NewClient(nd.URL)
+	d, err := fixture.NewDecoder(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(c, d, opts...)
+
+	out := make(chan Record)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Scan(context.Background(), startSlot, endSlot, out)
+		close(out)
+	}()
+	var records []Record
+	for record := range out {
+		records = append(records, record)
+	}
+	return records, <-done
+}
+
+// transfers decodes the transfers of a fixture.
+func transfers(t *testing.T, signature string) []*decoder.Transfer {
+	t.Helper()
+	d, err := fixture.NewDecoder(fixtureDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfers, err := d.DecodeSignature(context.Background(), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transfers
+}
+
+func TestScan(t *testing.T) {
+	nd := newNode(t, &node{
+		blocks: []uint64{10, 11, 12, 13, 14, 15, 16},
+		txs: map[uint64][]blockTx{
+			10: {loadBlockTx(t, tokenTransferSignature, false)},
+			// The failed transaction is left out, and the other keeps its index
+			13: {loadBlockTx(t, tokenTransferSignature, true), loadBlockTx(t, ataCreationSignature, false)},
+		},
+		// Listed by getBlocks but skipped by the time getBlock asks
+		errors: map[uint64]int{11: errCodeSlotSkipped, 12: errCodeLongTermStorageSlotSkip},
+		// The first block is the slowest, so later ones finish first
+		slow:  map[uint64]bool{10: true},
+		delay: 200 * time.Millisecond,
+	})
+	checkpoint := &memoryCheckpoint{}
+	const concurrency = 2
+	records, err := scan(t, nd, 10, 20, WithConcurrency(concurrency), WithCheckpoint(checkpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		slot      uint64
+		signature string
+		index     int
+		transfer  *decoder.Transfer
+	}
+	var wants []want
+	for _, transfer := range transfers(t, tokenTransferSignature) {
+		wants = append(wants, want{10, tokenTransferSignature, 0, transfer})
+	}
+	for _, transfer := range transfers(t, ataCreationSignature) {
+		wants = append(wants, want{13, ataCreationSignature, 1, transfer})
+	}
+	if len(records) != len(wants) {
+		t.Fatalf("got %d records, want %d", len(records), len(wants))
+	}
+	for i, w := range wants {
+		r := records[i]
+		if r.Slot != w.slot || r.Signature != w.signature || r.Index != w.index ||
+			r.Transfer.Source != w.transfer.Source || r.Transfer.Amount != w.transfer.Amount {
+			t.Errorf("record %d is %d/%s/%d %+v, want %d/%s/%d %+v", i,
+				r.Slot, r.Signature, r.Index, r.Transfer, w.slot, w.signature, w.index, w.transfer)
+		}
+	}
+
+	if nd.maxInflight > concurrency {
+		t.Errorf("%d blocks were fetched at once, want at most %d", nd.maxInflight, concurrency)
+	}
+	// Every block is saved once its records are sent, and the end of the range once it is done
+	wantSaved := []uint64{10, 11, 12, 13, 14, 15, 16, 20}
+	if len(checkpoint.saved) != len(wantSaved) {
+		t.Fatalf("saved checkpoints %v, want %v", checkpoint.saved, wantSaved)
+	}
+	for i := range wantSaved {
+		if checkpoint.saved[i] != wantSaved[i] {
+			t.Fatalf("saved checkpoints %v, want %v", checkpoint.saved, wantSaved)
+		}
+	}
+}
+
+func TestScanResume(t *testing.T) {
+	nd := newNode(t, &node{
+		blocks: []uint64{10, 13},
+		txs: map[uint64][]blockTx{
+			10: {loadBlockTx(t, tokenTransferSignature, false)},
+			13: {loadBlockTx(t, ataCreationSignature, false)},
+		},
+	})
+	checkpoint := FileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+	if err := checkpoint.Save(10); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := scan(t, nd, 10, 20, WithCheckpoint(checkpoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nd.getBlocks) != 1 || nd.getBlocks[0] != [2]uint64{11, 20} {
+		t.Errorf("listed blocks %v, want [[11 20]]", nd.getBlocks)
+	}
+	for _, record := range records {
+		if record.Slot != 13 {
+			t.Errorf("got a record of slot %d, which was done before the checkpoint", record.Slot)
+		}
+	}
+	if want := len(transfers(t, ataCreationSignature)); len(records) != want {
+		t.Errorf("got %d records, want %d", len(records), want)
+	}
+	if slot, ok, err := checkpoint.Load(); err != nil || !ok || slot != 20 {
+		t.Errorf("checkpoint is %d, %t, %v, want 20", slot, ok, err)
+	}
+
+	// A finished range has nothing left to scan
+	nd.getBlocks = nil
+	if records, err := scan(t, nd, 10, 20, WithCheckpoint(checkpoint)); err != nil || len(records) != 0 || len(nd.getBlocks) != 0 {
+		t.Errorf("rescan got %d records, error %v and listed %v, want nothing", len(records), err, nd.getBlocks)
+	}
+}
+
+func TestScanBlockError(t *testing.T) {
+	nd := newNode(t, &node{
+		blocks: []uint64{10, 11},
+		txs:    map[uint64][]blockTx{10: {loadBlockTx(t, tokenTransferSignature, false)}},
+		errors: map[uint64]int{11: -32603},
+	})
+	checkpoint := &memoryCheckpoint{}
+	_, err := scan(t, nd, 10, 20, WithCheckpoint(checkpoint))
+	var rpcErr *rpc.JsonRpcError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32603 {
+		t.Errorf("got %v, want the getBlock error", err)
+	}
+	// The failed block is not saved, so a rerun fetches it again
+	if len(checkpoint.saved) != 1 || checkpoint.saved[0] != 10 {
+		t.Errorf("saved checkpoints %v, want [10]", checkpoint.saved)
+	}
+}
@@ -0,0 +1,176 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket endpoints of the public clusters, matching the rpc package's HTTP endpoints.
+const (
+	LocalnetWSEndpoint = "ws://localhost:8900"
+	DevnetWSEndpoint   = "wss://api.devnet.solana.com"
+	TestnetWSEndpoint  = "wss://api.testnet.solana.com"
+	MainnetWSEndpoint  = "wss://api.mainnet-beta.solana.com"
+)
+
+const (
+	pingInterval = 20 * time.Second
+	readTimeout  = 60 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+// WebsocketEndpoint derives the WebSocket URL of an HTTP RPC endpoint.
+// A validator serves WebSocket one port above its HTTP port, so the explicit port of a plain http endpoint,
+// such as a local test validator, is incremented.
+func WebsocketEndpoint(rpcEndpoint string) (string, error) {
+	u, err := url.Parse(rpcEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid rpc endpoint %q: %w", rpcEndpoint, err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+		port := u.Port()
+		if port == "" {
+			break
+		}
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return "", fmt.Errorf("invalid rpc endpoint port %q: %w", port, err)
+		}
+		u.Host = strings.TrimSuffix(u.Host, ":"+port) + ":" + strconv.Itoa(n+1)
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported rpc endpoint scheme %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// message is any JSON-RPC message received over the socket: a response to a request or a notification.
+type message struct {
+	ID     *uint64           `json:"id"`
+	Result json.RawMessage   `json:"result"`
+	Error  *rpc.JsonRpcError `json:"error"`
+	Method string            `json:"method"`
+	Params struct {
+		Result       json.RawMessage `json:"result"`
+		Subscription uint64          `json:"subscription"`
+	} `json:"params"`
+}
+
+// logsNotification is the result of a logsNotification message.
+type logsNotification struct {
+	Context struct {
+		Slot uint64 `json:"slot"`
+	} `json:"context"`
+	Value struct {
+		Signature string `json:"signature"`
+		Err       any    `json:"err"`
+	} `json:"value"`
+}
+
+// signatureNotification is the result of a signatureNotification message.
+type signatureNotification struct {
+	Context struct {
+		Slot uint64 `json:"slot"`
+	} `json:"context"`
+	Value struct {
+		Err any `json:"err"`
+	} `json:"value"`
+}
+
+// conn is a WebSocket connection speaking the Solana pubsub protocol.
+type conn struct {
+	ws     *websocket.Conn
+	nextID uint64
+	done   chan struct{}
+	once   sync.Once
+}
+
+func dial(ctx context.Context, dialer *websocket.Dialer, endpoint string) (*conn, error) {
+	ws, _, err := dialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", endpoint, err)
+	}
+	c := &conn{ws: ws, done: make(chan struct{})}
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	go c.keepAlive()
+	return c, nil
+}
+
+// keepAlive pings the server so idle subscriptions are not dropped and dead connections are noticed.
+func (c *conn) keepAlive() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Close closes the connection. It may be called more than once, and concurrently with a read.
+func (c *conn) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		err = c.ws.Close()
+	})
+	return err
+}
+
+// subscribe sends a subscription request and waits for the subscription id.
+func (c *conn) subscribe(method string, params ...any) (uint64, error) {
+	c.nextID++
+	id := c.nextID
+	request := rpc.JsonRpcRequest{JsonRpc: "2.0", Id: id, Method: method, Params: params}
+	c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := c.ws.WriteJSON(request); err != nil {
+		return 0, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return 0, err
+		}
+		if msg.ID == nil || *msg.ID != id {
+			continue
+		}
+		if msg.Error != nil {
+			return 0, fmt.Errorf("%s failed: %w", method, msg.Error)
+		}
+		var subscription uint64
+		if err := json.Unmarshal(msg.Result, &subscription); err != nil {
+			return 0, fmt.Errorf("invalid %s response: %w", method, err)
+		}
+		return subscription, nil
+	}
+}
+
+// read returns the next message from the socket.
+func (c *conn) read() (*message, error) {
+	c.ws.SetReadDeadline(time.Now().Add(readTimeout))
+	var msg message
+	if err := c.ws.ReadJSON(&msg); err != nil {
+		return nil, fmt.Errorf("failed to read from websocket: %w", err)
+	}
+	return &msg, nil
+}
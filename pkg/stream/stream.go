@@ -0,0 +1,364 @@
+// Package stream watches confirmed transactions over WebSocket subscriptions and decodes their transfers as they land.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/gorilla/websocket"
+
+	"solana-starter/pkg/decoder"
+)
+
+// DefaultMaxBackfill is the most signatures fetched to fill the gap left by a reconnect unless WithMaxBackfill is given.
+const DefaultMaxBackfill = 1000
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+
+	// A transaction announced at confirmed commitment may not be served by getTransaction right away
+	notFoundRetries = 5
+	notFoundDelay   = 500 * time.Millisecond
+
+	// seenLimit bounds the signatures remembered to drop duplicates between backfill and notifications
+	seenLimit = 10_000
+)
+
+// ErrTransactionFailed is returned by WaitSignature when the transaction was confirmed with an error.
+var ErrTransactionFailed = errors.New("stream: transaction failed")
+
+// Record is a transfer seen by the stream, with the transaction that made it.
+type Record struct {
+	Signature string
+	Slot      uint64
+	Transfer  *decoder.Transfer
+}
+
+// Stream subscribes to an RPC node's WebSocket endpoint and decodes what it is notified of.
+type Stream struct {
+	endpoint    string
+	c           *client.Client
+	d           *decoder.Decoder
+	dialer      *websocket.Dialer
+	maxBackfill int
+	errorLog    *log.Logger
+}
+
+// Option configures a Stream.
+type Option func(*Stream)
+
+// WithDialer sets the dialer used to open WebSocket connections.
+func WithDialer(dialer *websocket.Dialer) Option {
+	return func(s *Stream) {
+		s.dialer = dialer
+	}
+}
+
+// WithMaxBackfill sets the most signatures fetched over HTTP after a reconnect; zero disables the backfill.
+func WithMaxBackfill(n int) Option {
+	return func(s *Stream) {
+		if n >= 0 {
+			s.maxBackfill = n
+		}
+	}
+}
+
+// WithErrorLog sets the logger told about transactions Watch skips; by default the log package's standard logger is used.
+func WithErrorLog(l *log.Logger) Option {
+	return func(s *Stream) {
+		s.errorLog = l
+	}
+}
+
+// New returns a Stream that subscribes at the WebSocket endpoint, backfills with c and decodes with d.
+// Notifications arrive at confirmed commitment, so d should be built with decoder.WithCommitment(rpc.CommitmentConfirmed).
+func New(endpoint string, c *client.Client, d *decoder.Decoder, opts ...Option) *Stream {
+	s := &Stream{
+		endpoint:    endpoint,
+		c:           c,
+		d:           d,
+		dialer:      websocket.DefaultDialer,
+		maxBackfill: DefaultMaxBackfill,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Watch sends to out the transfers of every confirmed transaction that mentions address,
+// which may be a wallet, a token account or a program such as the token program.
+// A dropped connection is reopened with exponential backoff, and the transactions missed meanwhile
+// are fetched with getSignaturesForAddress and sent oldest first before live notifications resume.
+// Until a transaction has been sent, the transactions missed are those since the slot the first connection
+// subscribed at.
+// Failed transactions are skipped, and so are transactions that cannot be fetched or decoded, after logging
+// the error; why a connection ended is logged too. Watch runs until the context is cancelled; it does not close out.
+func (s *Stream) Watch(ctx context.Context, address string, out chan<- Record) error {
+	w := &watch{Stream: s, address: address, out: out, seen: make(map[string]struct{})}
+	backoff := minBackoff
+	for {
+		err := w.run(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if w.live {
+			backoff = minBackoff
+		}
+		w.logf("stream: watching %s: %v; reconnecting in %v", address, err, backoff)
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// watch is the state of a Watch call that outlives a single connection.
+type watch struct {
+	*Stream
+	address string
+	out     chan<- Record
+
+	since uint64 // slot the first connection subscribed at, where the next backfill stops while last is empty
+	last  string // newest signature handled, where the next backfill stops
+	live  bool   // whether the last connection subscribed and caught up, so the next one starts over from minBackoff
+	seen  map[string]struct{}
+	order []string
+}
+
+// run serves one connection until it fails.
+func (w *watch) run(ctx context.Context) error {
+	w.live = false
+	conn, err := dial(ctx, w.dialer, w.endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	_, err = conn.subscribe("logsSubscribe",
+		map[string]any{"mentions": []string{w.address}},
+		map[string]any{"commitment": rpc.CommitmentConfirmed},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe first so nothing between the backfill and the first notification is missed; the overlap is deduplicated
+	if w.since == 0 {
+		slot, err := w.c.GetSlotWithConfig(ctx, client.GetSlotConfig{Commitment: rpc.CommitmentConfirmed})
+		if err != nil {
+			return fmt.Errorf("failed to get slot: %w", err)
+		}
+		w.since = slot
+	} else if err := w.backfill(ctx); err != nil {
+		return err
+	}
+	// A quiet address may send nothing before the connection drops; that is no reason to back off further
+	w.live = true
+
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			return err
+		}
+		if msg.Method != "logsNotification" {
+			continue
+		}
+		var notification logsNotification
+		if err := json.Unmarshal(msg.Params.Result, &notification); err != nil {
+			return fmt.Errorf("invalid logsNotification: %w", err)
+		}
+		if notification.Value.Err != nil {
+			continue
+		}
+		if err := w.emit(ctx, notification.Value.Signature, notification.Context.Slot); err != nil {
+			return err
+		}
+	}
+}
+
+// backfill sends the successful transactions of the address newer than the last one handled,
+// or, if none was, the ones since the slot the watch started at.
+func (w *watch) backfill(ctx context.Context) error {
+	var missed []rpc.SignatureWithStatus
+	before := ""
+	for len(missed) < w.maxBackfill {
+		signatures, err := w.c.GetSignaturesForAddressWithConfig(ctx, w.address, client.GetSignaturesForAddressConfig{
+			Limit:      min(w.maxBackfill-len(missed), DefaultMaxBackfill),
+			Before:     before,
+			Until:      w.last,
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get signatures until %q: %w", w.last, err)
+		}
+		if len(signatures) == 0 {
+			break
+		}
+		if w.last == "" {
+			// Signatures come newest first, so the older ones all come after the first one before the start slot
+			n := len(signatures)
+			for i, signature := range signatures {
+				if signature.Slot < w.since {
+					n = i
+					break
+				}
+			}
+			missed = append(missed, signatures[:n]...)
+			if n < len(signatures) {
+				break
+			}
+		} else {
+			missed = append(missed, signatures...)
+		}
+		before = signatures[len(signatures)-1].Signature
+	}
+
+	// Signatures come newest first
+	for i := len(missed) - 1; i >= 0; i-- {
+		if missed[i].Err != nil {
+			continue
+		}
+		if err := w.emit(ctx, missed[i].Signature, missed[i].Slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emit decodes a transaction once and sends its transfers.
+// A transaction that cannot be fetched or decoded is logged and skipped, so it does not stop the watch.
+func (w *watch) emit(ctx context.Context, signature string, slot uint64) error {
+	if _, ok := w.seen[signature]; ok {
+		return nil
+	}
+	transfers, err := w.decode(ctx, signature)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		w.logf("stream: skipping %s: %v", signature, err)
+	}
+	for _, transfer := range transfers {
+		select {
+		case w.out <- Record{Signature: signature, Slot: slot, Transfer: transfer}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	w.seen[signature] = struct{}{}
+	w.order = append(w.order, signature)
+	if len(w.order) > seenLimit {
+		delete(w.seen, w.order[0])
+		w.order = w.order[1:]
+	}
+	w.last = signature
+	return nil
+}
+
+func (s *Stream) logf(format string, args ...any) {
+	if s.errorLog != nil {
+		s.errorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// decode decodes a signature, waiting briefly for nodes that have not indexed it yet.
+func (s *Stream) decode(ctx context.Context, signature string) ([]*decoder.Transfer, error) {
+	for attempt := 0; ; attempt++ {
+		transfers, err := s.d.DecodeSignature(ctx, signature)
+		if !errors.Is(err, decoder.ErrTransactionNotFound) || attempt == notFoundRetries {
+			return transfers, err
+		}
+		if err := sleep(ctx, notFoundDelay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// WaitSignature waits until signature is confirmed and returns its transfers.
+// It returns ErrTransactionFailed if the transaction was confirmed with an error.
+// A dropped connection is reopened, and the signature status is checked over HTTP after each
+// subscription in case the transaction was confirmed before it.
+func (s *Stream) WaitSignature(ctx context.Context, signature string) ([]*decoder.Transfer, error) {
+	backoff := minBackoff
+	for {
+		confirmed, failed, err := s.waitSignature(ctx, signature)
+		if err == nil {
+			if failed {
+				return nil, fmt.Errorf("%w: %s", ErrTransactionFailed, signature)
+			}
+			if confirmed {
+				return s.decode(ctx, signature)
+			}
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// waitSignature waits on one connection for the signature to be confirmed.
+func (s *Stream) waitSignature(ctx context.Context, signature string) (confirmed, failed bool, err error) {
+	conn, err := dial(ctx, s.dialer, s.endpoint)
+	if err != nil {
+		return false, false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	_, err = conn.subscribe("signatureSubscribe", signature, map[string]any{"commitment": rpc.CommitmentConfirmed})
+	if err != nil {
+		return false, false, err
+	}
+
+	status, err := s.c.GetSignatureStatus(ctx, signature)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get signature status: %w", err)
+	}
+	if status != nil && status.ConfirmationStatus != nil && *status.ConfirmationStatus != rpc.CommitmentProcessed {
+		return true, status.Err != nil, nil
+	}
+
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			return false, false, err
+		}
+		if msg.Method != "signatureNotification" {
+			continue
+		}
+		var notification signatureNotification
+		if err := json.Unmarshal(msg.Params.Result, &notification); err != nil {
+			return false, false, fmt.Errorf("invalid signatureNotification: %w", err)
+		}
+		return true, notification.Value.Err != nil, nil
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,281 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/rpc"
+	"github.com/gorilla/websocket"
+
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/fixture"
+)
+
+// Transactions saved in the decoder's fixtures, with one and two transfers.
+const (
+	solTransferSignature   = "4Dj8Xbs7L6z7pbNp5eGZXLmYZLwePPRVTfunjx2EWDc4nwtVYRq4YqduiFKXR23cGqmbF6LHoubGnKa7gCozstGF"
+	tokenTransferSignature = "4fSTSDTTuYa1XXAFxFenuY3SoZWUwCzpMq7kUiya1zW6uqqh6C76GFqTQ3wvegEbZhbPJyr33iDAbieQVWCtVXmf"
+
+	fixtureDir = "../decoder/testdata"
+	watchSlot  = 100
+)
+
+// newRPCServer serves getSlot and getSignaturesForAddress itself, fails getTransaction for "bad",
+// and answers everything else from the decoder's fixtures. The until parameter of every
+// getSignaturesForAddress request is sent to untils.
+func newRPCServer(t *testing.T, signatures []rpc.SignatureWithStatus, untils chan<- string) *httptest.Server {
+	transport := &fixture.Transport{Dir: fixtureDir}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return
+		}
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			return
+		}
+
+		res := rpc.JsonRpcResponse[any]{JsonRpc: "2.0", Id: req.ID}
+		switch req.Method {
+		case "getSlot":
+			res.Result = watchSlot
+		case "getSignaturesForAddress":
+			var cfg struct {
+				Until string `json:"until"`
+			}
+			if len(req.Params) > 1 {
+				json.Unmarshal(req.Params[1], &cfg)
+			}
+			// Only the first backfill, which has no signature to stop at, finds anything
+			if cfg.Until == "" {
+				res.Result = signatures
+			} else {
+				res.Result = []rpc.SignatureWithStatus{}
+			}
+			untils <- cfg.Until
+		case "getTransaction":
+			if strings.Contains(string(req.Params[0]), "bad") {
+				res.Error = &rpc.JsonRpcError{Code: -32603, Message: "internal error"}
+				break
+			}
+			fallthrough
+		default:
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fixtureRes, err := transport.RoundTrip(r)
+			if err != nil {
+				t.Errorf("fixture: %v", err)
+				return
+			}
+			defer fixtureRes.Body.Close()
+			w.Header().Set("Content-Type", "application/json")
+			io.Copy(w, fixtureRes.Body)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newWSServer accepts logsSubscribe on every connection, then plays the messages scripted for that connection
+// and closes it. Connections past the script stay open until the client closes them.
+// If connected is not nil, the time of every connection is sent to it.
+func newWSServer(t *testing.T, script [][]any, connected chan<- time.Time) *httptest.Server {
+	var upgrader websocket.Upgrader
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		defer ws.Close()
+		if connected != nil {
+			connected <- time.Now()
+		}
+		n := connections
+		connections++
+
+		var req rpc.JsonRpcRequest
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+		if req.Method != "logsSubscribe" {
+			t.Errorf("got %s, want logsSubscribe", req.Method)
+			return
+		}
+		if err := ws.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.Id, "result": 1}); err != nil {
+			return
+		}
+
+		if n >= len(script) {
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+		for _, msg := range script[n] {
+			if err := ws.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newLogsNotification(signature string, slot uint64) map[string]any {
+	return map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "logsNotification",
+		"params": map[string]any{
+			"subscription": 1,
+			"result": map[string]any{
+				"context": map[string]any{"slot": slot},
+				"value":   map[string]any{"signature": signature, "err": nil, "logs": []string{}},
+			},
+		},
+	}
+}
+
+func TestWatchReconnectsAndBackfills(t *testing.T) {
+	untils := make(chan string, 10)
+	rpcServer := newRPCServer(t, []rpc.SignatureWithStatus{
+		{Signature: solTransferSignature, Slot: watchSlot + 1},
+		// Older than the slot the watch started at, so not backfilled
+		{Signature: tokenTransferSignature, Slot: watchSlot - 1},
+	}, untils)
+	wsServer := newWSServer(t, [][]any{
+		// Dropped before any notification: the reconnect backfills from the start slot
+		nil,
+		// The failed getTransaction is skipped rather than ending the watch
+		{newLogsNotification("bad", watchSlot+2), newLogsNotification(tokenTransferSignature, watchSlot+3)},
+	}, nil)
+
+	tokens, err := decoder.LoadTokenCache(filepath.Join(fixtureDir, fixture.TokensFile), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewClient(rpcServer.URL)
+	d := decoder.New(c, decoder.WithCommitment(rpc.CommitmentConfirmed), decoder.WithTokenCache(tokens))
+	var errorLog bytes.Buffer
+	s := New("ws"+strings.TrimPrefix(wsServer.URL, "http"), c, d, WithErrorLog(log.New(&errorLog, "", 0)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	records := make(chan Record)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Watch(ctx, "address", records)
+	}()
+
+	want := []struct {
+		signature string
+		slot      uint64
+	}{
+		{solTransferSignature, watchSlot + 1},
+		{tokenTransferSignature, watchSlot + 3},
+		{tokenTransferSignature, watchSlot + 3},
+	}
+	var got []Record
+	for len(got) < len(want) {
+		select {
+		case record := <-records:
+			got = append(got, record)
+		case <-ctx.Done():
+			t.Fatalf("got %d records before timing out, want %d", len(got), len(want))
+		}
+	}
+
+	// The third connection backfills from the last transaction handled
+	wantUntils := []string{"", tokenTransferSignature}
+	var gotUntils []string
+	for len(gotUntils) < len(wantUntils) {
+		select {
+		case until := <-untils:
+			gotUntils = append(gotUntils, until)
+		case <-ctx.Done():
+			t.Fatalf("got backfills until %q before timing out, want %q", gotUntils, wantUntils)
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Watch returned %v, want %v", err, context.Canceled)
+	}
+
+	if !slices.Equal(gotUntils, wantUntils) {
+		t.Errorf("got backfills until %q, want %q", gotUntils, wantUntils)
+	}
+	for i, record := range got {
+		if record.Signature != want[i].signature || record.Slot != want[i].slot {
+			t.Errorf("record %d is %s at slot %d, want %s at slot %d", i, record.Signature, record.Slot, want[i].signature, want[i].slot)
+		}
+		if record.Transfer == nil {
+			t.Errorf("record %d has no transfer", i)
+		}
+	}
+	if !strings.Contains(errorLog.String(), "skipping bad") {
+		t.Errorf("skipped transaction not logged, log: %q", errorLog.String())
+	}
+	if !strings.Contains(errorLog.String(), "reconnecting in") {
+		t.Errorf("dropped connection not logged, log: %q", errorLog.String())
+	}
+}
+
+// TestWatchQuietAddress checks that connections which subscribe but see no transaction before dropping
+// are reopened after minBackoff every time, instead of backing off towards maxBackoff.
+func TestWatchQuietAddress(t *testing.T) {
+	const connections = 4
+	untils := make(chan string, connections)
+	rpcServer := newRPCServer(t, nil, untils)
+	connected := make(chan time.Time, connections+1)
+	wsServer := newWSServer(t, make([][]any, connections), connected)
+
+	c := client.NewClient(rpcServer.URL)
+	d := decoder.New(c, decoder.WithCommitment(rpc.CommitmentConfirmed))
+	s := New("ws"+strings.TrimPrefix(wsServer.URL, "http"), c, d, WithErrorLog(log.New(io.Discard, "", 0)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Watch(ctx, "address", make(chan Record))
+	}()
+
+	var times []time.Time
+	for len(times) < connections {
+		select {
+		case at := <-connected:
+			times = append(times, at)
+		case <-ctx.Done():
+			t.Fatalf("got %d connections before timing out, want %d", len(times), connections)
+		}
+	}
+	cancel()
+	<-done
+
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap >= 2*minBackoff {
+			t.Errorf("reconnect %d came after %v, want about %v", i, gap, minBackoff)
+		}
+	}
+}
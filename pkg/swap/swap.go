@@ -0,0 +1,250 @@
+// Package swap interprets the transfers the decoder finds under an AMM or aggregator instruction
+// as a single trade by the transaction's signer.
+package swap
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/mr-tron/base58"
+	"github.com/shopspring/decimal"
+
+	"solana-starter/pkg/decoder"
+)
+
+// Leg is an amount of one token that changed hands in a swap.
+type Leg struct {
+	TokenAddress string `json:"tokenAddress"`
+	Symbol       string `json:"symbol"`
+	Decimals     uint8  `json:"decimals"`
+	Amount       string `json:"amount"` // in base units; negative in Swap.Legs for tokens the trader paid
+	UiAmount     string `json:"uiAmount"`
+}
+
+// Swap is a trade made by one outer instruction of a transaction.
+type Swap struct {
+	Signature             string `json:"signature"`
+	Trader                string `json:"trader"` // the fee payer, whose wallet the legs are relative to
+	ProgramID             string `json:"programID"`
+	ProgramName           string `json:"programName,omitempty"`
+	OuterInstructionIndex int    `json:"outerInstructionIndex"`
+	// Input is what the trader paid and Output what they received. Both are empty when the swap is ambiguous.
+	Input  Leg `json:"input"`
+	Output Leg `json:"output"`
+	// Price is the effective price: Input UI amount paid per unit of Output, fees included.
+	Price string `json:"price"`
+	// Legs is the trader's net change in every token the instruction moved for them.
+	Legs []Leg `json:"legs"`
+	// MultiHop is set when the route went through tokens the trader neither paid nor received.
+	MultiHop bool `json:"multiHop"`
+	// Ambiguous is set when the trader's net changes are not exactly one token paid and one received.
+	Ambiguous bool                `json:"ambiguous"`
+	Transfers []*decoder.Transfer `json:"-"` // the transfers the swap was built from
+}
+
+// Interpreter finds swaps in transactions.
+type Interpreter struct {
+	d        *decoder.Decoder
+	programs *decoder.ProgramRegistry
+}
+
+// Option configures an Interpreter.
+type Option func(*Interpreter)
+
+// WithProgramRegistry sets the registry that tells swap programs apart from token and system programs.
+func WithProgramRegistry(registry *decoder.ProgramRegistry) Option {
+	return func(in *Interpreter) {
+		in.programs = registry
+	}
+}
+
+// New returns an Interpreter that fetches and decodes transactions with d, at its commitment.
+// Unless WithProgramRegistry is given it uses decoder.NewProgramRegistry.
+func New(d *decoder.Decoder, opts ...Option) *Interpreter {
+	in := &Interpreter{d: d}
+	for _, opt := range opts {
+		opt(in)
+	}
+	if in.programs == nil {
+		in.programs = decoder.NewProgramRegistry()
+	}
+	return in
+}
+
+// DecodeSignature fetches the transaction identified by signature and returns its swaps.
+func (in *Interpreter) DecodeSignature(ctx context.Context, signature string) ([]*Swap, error) {
+	tx, err := in.d.GetTransaction(ctx, signature)
+	if err != nil {
+		return nil, err
+	}
+	return in.DecodeTransaction(ctx, tx)
+}
+
+// DecodeTransaction returns the swaps of an already-fetched transaction, in instruction order.
+// A failed transaction traded nothing, so decoder.ErrTransactionFailed is returned for it
+// whatever the decoder's options.
+func (in *Interpreter) DecodeTransaction(ctx context.Context, tx *client.Transaction) ([]*Swap, error) {
+	if tx != nil && tx.Meta != nil && tx.Meta.Err != nil {
+		return nil, fmt.Errorf("%w: %v", decoder.ErrTransactionFailed, tx.Meta.Err)
+	}
+	transfers, err := in.d.DecodeTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Transaction.Message.Accounts) == 0 {
+		return nil, nil
+	}
+	trader := tx.Transaction.Message.Accounts[0].ToBase58()
+
+	swaps := in.Interpret(trader, transfers)
+	if len(tx.Transaction.Signatures) > 0 {
+		signature := base58.Encode(tx.Transaction.Signatures[0])
+		for _, swap := range swaps {
+			swap.Signature = signature
+		}
+	}
+	return swaps, nil
+}
+
+// Interpret groups transfers by the outer instruction that caused them and returns a swap for every
+// instruction of a program that is not a token or system program, in which the trader both paid and received.
+func (in *Interpreter) Interpret(trader string, transfers []*decoder.Transfer) []*Swap {
+	var order []int
+	var groups = make(map[int][]*decoder.Transfer)
+	for _, transfer := range transfers {
+		if !transfer.IsInnerInstruction || !in.isSwapProgram(transfer.OuterInstructionProgramID) {
+			continue
+		}
+		index := transfer.OuterInstructionIndex
+		if _, ok := groups[index]; !ok {
+			order = append(order, index)
+		}
+		groups[index] = append(groups[index], transfer)
+	}
+
+	var swaps []*Swap
+	for _, index := range order {
+		if swap := interpret(trader, groups[index]); swap != nil {
+			swap.ProgramName = in.programs.Name(swap.ProgramID)
+			swaps = append(swaps, swap)
+		}
+	}
+	return swaps
+}
+
+// isSwapProgram reports whether transfers under programID may be a trade. Unknown programs qualify,
+// so swaps on AMMs missing from the registry are still found.
+func (in *Interpreter) isSwapProgram(programID string) bool {
+	program, ok := in.programs.Lookup(programID)
+	if !ok {
+		return true
+	}
+	return program.Category != decoder.CategoryToken && program.Category != decoder.CategorySystem
+}
+
+// interpret builds the swap of one outer instruction from its transfers.
+func interpret(trader string, transfers []*decoder.Transfer) *Swap {
+	var net = make(map[string]*big.Int)
+	var tokens = make(map[string]*decoder.Transfer) // a transfer of each mint, for its token info
+	var mints = make(map[string]struct{})
+	for _, transfer := range transfers {
+		mints[transfer.TokenAddress] = struct{}{}
+		amount, ok := new(big.Int).SetString(transfer.Amount, 10)
+		if !ok {
+			continue
+		}
+		// The fee withheld by Token-2022 never reaches the destination
+		received := new(big.Int).Set(amount)
+		if fee, ok := new(big.Int).SetString(transfer.Fee, 10); ok {
+			received.Sub(received, fee)
+		}
+
+		paid := transfer.SourceOwner == trader || (transfer.SourceOwner == "" && transfer.Authority == trader)
+		receives := transfer.DestinationOwner == trader
+		if paid == receives {
+			// Between two accounts of the trader, e.g. wrapping SOL, or not involving them at all
+			continue
+		}
+		if _, ok := net[transfer.TokenAddress]; !ok {
+			net[transfer.TokenAddress] = new(big.Int)
+			tokens[transfer.TokenAddress] = transfer
+		}
+		if paid {
+			net[transfer.TokenAddress].Sub(net[transfer.TokenAddress], amount)
+		} else {
+			net[transfer.TokenAddress].Add(net[transfer.TokenAddress], received)
+		}
+	}
+
+	var legs []Leg
+	var inputs, outputs []Leg
+	for mint, amount := range net {
+		if amount.Sign() == 0 {
+			continue
+		}
+		leg := newLeg(tokens[mint], amount)
+		legs = append(legs, leg)
+		if amount.Sign() < 0 {
+			inputs = append(inputs, leg)
+		} else {
+			outputs = append(outputs, leg)
+		}
+	}
+	if len(inputs) == 0 || len(outputs) == 0 {
+		// The trader only paid or only received: a deposit, withdrawal or someone else's trade
+		return nil
+	}
+	sort.Slice(legs, func(i, j int) bool { return legs[i].TokenAddress < legs[j].TokenAddress })
+
+	swap := &Swap{
+		Trader:                trader,
+		ProgramID:             transfers[0].OuterInstructionProgramID,
+		OuterInstructionIndex: transfers[0].OuterInstructionIndex,
+		Legs:                  legs,
+		MultiHop:              len(mints) > len(legs),
+		Ambiguous:             len(inputs) != 1 || len(outputs) != 1,
+		Transfers:             transfers,
+	}
+	if !swap.Ambiguous {
+		swap.Input = inputs[0]
+		swap.Input.Amount = swap.Input.Amount[1:]
+		swap.Input.UiAmount = uiAmount(swap.Input.Amount, swap.Input.Decimals)
+		swap.Output = outputs[0]
+		swap.Price = price(swap.Input, swap.Output)
+	}
+	return swap
+}
+
+func newLeg(token *decoder.Transfer, amount *big.Int) Leg {
+	return Leg{
+		TokenAddress: token.TokenAddress,
+		Symbol:       token.Symbol,
+		Decimals:     token.Decimals,
+		Amount:       amount.String(),
+		UiAmount:     uiAmount(amount.String(), token.Decimals),
+	}
+}
+
+// price returns the input UI amount per unit of output, or "" if the output amount is zero.
+func price(input, output Leg) string {
+	in, err := decimal.NewFromString(input.UiAmount)
+	if err != nil {
+		return ""
+	}
+	out, err := decimal.NewFromString(output.UiAmount)
+	if err != nil || out.IsZero() {
+		return ""
+	}
+	return in.Div(out).String()
+}
+
+func uiAmount(amount string, decimals uint8) string {
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return ""
+	}
+	return value.Shift(-int32(decimals)).String()
+}
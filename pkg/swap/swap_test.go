@@ -0,0 +1,37 @@
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/fixture"
+	"solana-starter/pkg/swap"
+)
+
+// fixtures are the decoder's saved transactions.
+const fixtures = "../decoder/testdata"
+
+func TestDecodeFailedTransaction(t *testing.T) {
+	signatures, err := fixture.Signatures(fixtures)
+	if err != nil || len(signatures) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	tx, err := fixture.LoadTransaction(context.Background(), filepath.Join(fixtures, signatures[0]+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Meta.Err = map[string]any{"InstructionError": []any{0, "Custom"}}
+
+	// Even a decoder decoding attempted transfers must not make a failed swap look like a trade
+	d, err := fixture.NewDecoder(fixtures, decoder.WithAttemptedTransfers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	swaps, err := swap.New(d).DecodeTransaction(context.Background(), tx)
+	if !errors.Is(err, decoder.ErrTransactionFailed) {
+		t.Errorf("got %d swaps and error %v, want %v", len(swaps), err, decoder.ErrTransactionFailed)
+	}
+}
@@ -0,0 +1,262 @@
+// Package tokenops builds the instructions of the common token program operations — creating a mint,
+// creating associated token accounts, minting, transferring and setting Metaplex metadata — and reads
+// the mint and metadata accounts they depend on. The token examples and the CLI share it.
+package tokenops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blocto/solana-go-sdk/client"
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/program/associated_token_account"
+	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/blocto/solana-go-sdk/program/system"
+	"github.com/blocto/solana-go-sdk/program/token"
+	"github.com/blocto/solana-go-sdk/types"
+)
+
+var (
+	ErrNotMint            = errors.New("tokenops: not a token mint")
+	ErrToken2022          = errors.New("tokenops: Token-2022 mints are not supported")
+	ErrIncompleteMetadata = errors.New("tokenops: name and symbol are required to create metadata")
+	ErrNothingToUpdate    = errors.New("tokenops: nothing to update")
+	ErrNotUpdateAuthority = errors.New("tokenops: not the update authority")
+)
+
+// CreateMintParam describes a new mint of the token program.
+type CreateMintParam struct {
+	Payer           common.PublicKey
+	Mint            common.PublicKey // the new account, which must sign
+	MintAuthority   common.PublicKey
+	FreezeAuthority *common.PublicKey // nil if accounts cannot be frozen
+	Decimals        uint8
+}
+
+// CreateMint returns the instructions creating the mint account, funded with its rent exemption, and initializing it.
+func CreateMint(ctx context.Context, c *client.Client, p CreateMintParam) ([]types.Instruction, error) {
+	rent, err := c.GetMinimumBalanceForRentExemption(ctx, token.MintAccountSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rent exemption: %w", err)
+	}
+	return []types.Instruction{
+		system.CreateAccount(system.CreateAccountParam{
+			From:     p.Payer,
+			New:      p.Mint,
+			Owner:    common.TokenProgramID,
+			Lamports: rent,
+			Space:    token.MintAccountSize,
+		}),
+		token.InitializeMint(token.InitializeMintParam{
+			Decimals:   p.Decimals,
+			Mint:       p.Mint,
+			MintAuth:   p.MintAuthority,
+			FreezeAuth: p.FreezeAuthority,
+		}),
+	}, nil
+}
+
+// ATA returns the associated token account of owner for mint.
+func ATA(owner, mint common.PublicKey) (common.PublicKey, error) {
+	ata, _, err := common.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("failed to derive token account: %w", err)
+	}
+	return ata, nil
+}
+
+// CreateATA returns the associated token account of owner and an instruction creating it unless it exists,
+// so it can precede a mint or transfer to that account.
+func CreateATA(funder, owner, mint common.PublicKey) (common.PublicKey, types.Instruction, error) {
+	ata, err := ATA(owner, mint)
+	if err != nil {
+		return common.PublicKey{}, types.Instruction{}, err
+	}
+	return ata, associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+		Funder:                 funder,
+		Owner:                  owner,
+		Mint:                   mint,
+		AssociatedTokenAccount: ata,
+	}), nil
+}
+
+// MintToParam describes minting Amount base units to the associated token account of Owner.
+type MintToParam struct {
+	Payer     common.PublicKey // funds the token account if it does not exist
+	Owner     common.PublicKey
+	Mint      common.PublicKey
+	Authority common.PublicKey // the mint authority, which must sign
+	Amount    uint64
+	Decimals  uint8
+}
+
+// MintTo returns the instructions creating the owner's token account if needed and minting to it.
+func MintTo(p MintToParam) ([]types.Instruction, error) {
+	ata, create, err := CreateATA(p.Payer, p.Owner, p.Mint)
+	if err != nil {
+		return nil, err
+	}
+	return []types.Instruction{
+		create,
+		token.MintToChecked(token.MintToCheckedParam{
+			Mint:     p.Mint,
+			Auth:     p.Authority,
+			To:       ata,
+			Amount:   p.Amount,
+			Decimals: p.Decimals,
+		}),
+	}, nil
+}
+
+// TransferParam describes a transfer of Amount base units between the associated token accounts of From and To.
+type TransferParam struct {
+	Payer    common.PublicKey // funds the recipient's token account if it does not exist
+	From     common.PublicKey // the sending owner, which must sign
+	To       common.PublicKey // the receiving owner
+	Mint     common.PublicKey
+	Amount   uint64
+	Decimals uint8
+}
+
+// Transfer returns the instructions creating the recipient's token account if needed and transferring to it.
+func Transfer(p TransferParam) ([]types.Instruction, error) {
+	source, err := ATA(p.From, p.Mint)
+	if err != nil {
+		return nil, err
+	}
+	destination, create, err := CreateATA(p.Payer, p.To, p.Mint)
+	if err != nil {
+		return nil, err
+	}
+	return []types.Instruction{
+		create,
+		token.TransferChecked(token.TransferCheckedParam{
+			From:     source,
+			To:       destination,
+			Mint:     p.Mint,
+			Auth:     p.From,
+			Amount:   p.Amount,
+			Decimals: p.Decimals,
+		}),
+	}, nil
+}
+
+// SetMetadataParam describes the Metaplex metadata to create or update. Empty fields keep their
+// current values when updating.
+type SetMetadataParam struct {
+	Mint      common.PublicKey
+	Payer     common.PublicKey
+	Authority common.PublicKey // the mint authority to create, the update authority to update; it must sign
+	Name      string
+	Symbol    string
+	URI       string
+}
+
+// SetMetadata returns the instruction creating the metadata of the mint if current is nil, or else updating
+// current, along with the resulting data. A created account is mutable and has Authority as its update authority.
+// Updates keep creators, royalties and the collection.
+func SetMetadata(current *token_metadata.Metadata, p SetMetadataParam) (types.Instruction, token_metadata.DataV2, error) {
+	address, err := token_metadata.GetTokenMetaPubkey(p.Mint)
+	if err != nil {
+		return types.Instruction{}, token_metadata.DataV2{}, fmt.Errorf("failed to find metadata account: %w", err)
+	}
+	if current == nil {
+		if p.Name == "" || p.Symbol == "" {
+			return types.Instruction{}, token_metadata.DataV2{}, ErrIncompleteMetadata
+		}
+		data := token_metadata.DataV2{Name: p.Name, Symbol: p.Symbol, Uri: p.URI}
+		return token_metadata.CreateMetadataAccountV3(token_metadata.CreateMetadataAccountV3Param{
+			Metadata:                address,
+			Mint:                    p.Mint,
+			MintAuthority:           p.Authority,
+			Payer:                   p.Payer,
+			UpdateAuthority:         p.Authority,
+			UpdateAuthorityIsSigner: true,
+			IsMutable:               true,
+			Data:                    data,
+		}), data, nil
+	}
+
+	if p.Name == "" && p.Symbol == "" && p.URI == "" {
+		return types.Instruction{}, token_metadata.DataV2{}, ErrNothingToUpdate
+	}
+	if current.UpdateAuthority != p.Authority {
+		return types.Instruction{}, token_metadata.DataV2{}, fmt.Errorf("%w: the update authority of %s is %s, not %s",
+			ErrNotUpdateAuthority, p.Mint.ToBase58(), current.UpdateAuthority.ToBase58(), p.Authority.ToBase58())
+	}
+	data := token_metadata.DataV2{
+		Name:                 TrimPadding(current.Data.Name),
+		Symbol:               TrimPadding(current.Data.Symbol),
+		Uri:                  TrimPadding(current.Data.Uri),
+		SellerFeeBasisPoints: current.Data.SellerFeeBasisPoints,
+		Creators:             current.Data.Creators,
+		Collection:           current.Collection,
+		Uses:                 current.Uses,
+	}
+	if p.Name != "" {
+		data.Name = p.Name
+	}
+	if p.Symbol != "" {
+		data.Symbol = p.Symbol
+	}
+	if p.URI != "" {
+		data.Uri = p.URI
+	}
+	return token_metadata.UpdateMetadataAccountV2(token_metadata.UpdateMetadataAccountV2Param{
+		MetadataAccount: address,
+		UpdateAuthority: p.Authority,
+		Data:            &data,
+	}), data, nil
+}
+
+// GetMint fetches a mint of the token program, the only one these builders support.
+func GetMint(ctx context.Context, c *client.Client, mint common.PublicKey) (token.MintAccount, error) {
+	account, err := c.GetAccountInfo(ctx, mint.ToBase58())
+	if err != nil {
+		return token.MintAccount{}, fmt.Errorf("failed to get mint: %w", err)
+	}
+	if account.Owner == common.Token2022ProgramID {
+		return token.MintAccount{}, fmt.Errorf("%w: %s", ErrToken2022, mint.ToBase58())
+	}
+	return ParseMint(mint, account)
+}
+
+// ParseMint decodes a mint of the token or Token-2022 program; the extensions of the latter are ignored.
+func ParseMint(mint common.PublicKey, account client.AccountInfo) (token.MintAccount, error) {
+	if (account.Owner != common.TokenProgramID && account.Owner != common.Token2022ProgramID) ||
+		len(account.Data) < token.MintAccountSize {
+		return token.MintAccount{}, fmt.Errorf("%w: %s", ErrNotMint, mint.ToBase58())
+	}
+	mintAccount, err := token.MintAccountFromData(account.Data[:token.MintAccountSize])
+	if err != nil || !mintAccount.IsInitialized {
+		return token.MintAccount{}, fmt.Errorf("%w: %s", ErrNotMint, mint.ToBase58())
+	}
+	return mintAccount, nil
+}
+
+// GetMetadata fetches the Metaplex metadata of mint and its address. The metadata is nil if the mint has none.
+func GetMetadata(ctx context.Context, c *client.Client, mint common.PublicKey) (common.PublicKey, *token_metadata.Metadata, error) {
+	address, err := token_metadata.GetTokenMetaPubkey(mint)
+	if err != nil {
+		return common.PublicKey{}, nil, fmt.Errorf("failed to find metadata account: %w", err)
+	}
+	account, err := c.GetAccountInfo(ctx, address.ToBase58())
+	if err != nil {
+		return common.PublicKey{}, nil, fmt.Errorf("failed to get metadata account: %w", err)
+	}
+	if len(account.Data) == 0 {
+		return address, nil, nil
+	}
+	metadata, err := token_metadata.MetadataDeserialize(account.Data)
+	if err != nil {
+		return common.PublicKey{}, nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return address, &metadata, nil
+}
+
+// TrimPadding removes the NUL bytes Metaplex pads its fixed-size strings with.
+func TrimPadding(s string) string {
+	return strings.TrimRight(s, "\x00")
+}
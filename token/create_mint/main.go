@@ -3,35 +3,46 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/system"
-	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
-	"github.com/blocto/solana-go-sdk/types"
 	"log"
-)
 
-// GLndC8XmRT5o6oBLwn8scDNvFY5MuX78wxJQsW5tXctk
-var feePayer, _ = types.AccountFromBase58("D8i1DFhgxWkBC52kRUtDRkZL5J5bUDJXssJtsehWYT51txphk8ipWe8goFKJt6638vAmEHVxdovsjmfiHPvKPbS")
+	"github.com/blocto/solana-go-sdk/types"
 
-// HcNCxoni2Ln5si48s1w8r5TRVH296RQ1MzKeM9FctdPg
-var alice, _ = types.AccountFromBase58("5ob5v9uGyJstuENC4pu7ScCdkCPiAZXqFLhu3qUoHB8uePLchCpPmgWyXPZ24NxLBD8dUP6UFNNYXKsFJtFKie74")
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
+)
 
+// create_mint creates a mint with the configured authority (alice) as mint authority.
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, err := cfg.AuthorityAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// create a mint account
 	mint := types.NewAccount()
 	fmt.Println("mint:", mint.PublicKey.ToBase58())
 
-	// get rent
-	rentExemptionBalance, err := c.GetMinimumBalanceForRentExemption(
-		context.Background(),
-		token.MintAccountSize,
-	)
+	// create and initialize it, funded with its rent exemption
+	instructions, err := tokenops.CreateMint(context.Background(), c, tokenops.CreateMintParam{
+		Payer:         feePayer.PublicKey,
+		Mint:          mint.PublicKey,
+		MintAuthority: alice.PublicKey,
+		Decimals:      8,
+	})
 	if err != nil {
-		log.Fatalf("get min balacne for rent exemption, err: %v", err)
+		log.Fatal(err)
 	}
 
 	res, err := c.GetLatestBlockhash(context.Background())
@@ -43,21 +54,7 @@ func main() {
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        feePayer.PublicKey,
 			RecentBlockhash: res.Blockhash,
-			Instructions: []types.Instruction{
-				system.CreateAccount(system.CreateAccountParam{
-					From:     feePayer.PublicKey,
-					New:      mint.PublicKey,
-					Owner:    common.TokenProgramID,
-					Lamports: rentExemptionBalance,
-					Space:    token.MintAccountSize,
-				}),
-				token.InitializeMint(token.InitializeMintParam{
-					Decimals:   8,
-					Mint:       mint.PublicKey,
-					MintAuth:   alice.PublicKey,
-					FreezeAuth: nil,
-				}),
-			},
+			Instructions:    instructions,
 		}),
 		Signers: []types.Account{feePayer, mint},
 	})
@@ -70,7 +67,7 @@ func main() {
 		log.Fatalf("send tx error, err: %v\n", err)
 	}
 
-	fmt.Printf("check tx at: https://explorer.solana.com/tx/%s?cluster=devnet\n", sig)
+	fmt.Printf("check tx at: %s\n", cfg.ExplorerTxURL(sig))
 }
 
 /*
@@ -3,28 +3,42 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/associated_token_account"
-	"github.com/blocto/solana-go-sdk/rpc"
-	"github.com/blocto/solana-go-sdk/types"
 	"log"
-)
 
-// GLndC8XmRT5o6oBLwn8scDNvFY5MuX78wxJQsW5tXctk
-var feePayer, _ = types.AccountFromBase58("D8i1DFhgxWkBC52kRUtDRkZL5J5bUDJXssJtsehWYT51txphk8ipWe8goFKJt6638vAmEHVxdovsjmfiHPvKPbS")
+	"github.com/blocto/solana-go-sdk/types"
 
-// HcNCxoni2Ln5si48s1w8r5TRVH296RQ1MzKeM9FctdPg
-var alice, _ = types.AccountFromBase58("5ob5v9uGyJstuENC4pu7ScCdkCPiAZXqFLhu3qUoHB8uePLchCpPmgWyXPZ24NxLBD8dUP6UFNNYXKsFJtFKie74")
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
+)
 
-var mintPubkey = common.PublicKeyFromString("gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT")
+// defaultMint is the mint created by create_mint, used unless another is configured.
+const defaultMint = "gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT"
 
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, err := cfg.AuthorityAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mintPubkey, err := config.PublicKey(cfg.MintAddress(defaultMint))
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	ata, _, err := common.FindAssociatedTokenAddress(alice.PublicKey, mintPubkey)
+	ata, createATA, err := tokenops.CreateATA(feePayer.PublicKey, alice.PublicKey, mintPubkey)
 	if err != nil {
-		log.Fatalf("find ata error, err: %v", err)
+		log.Fatal(err)
 	}
 	fmt.Println("ata:", ata.ToBase58())
 
@@ -37,14 +51,7 @@ func main() {
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        feePayer.PublicKey,
 			RecentBlockhash: res.Blockhash,
-			Instructions: []types.Instruction{
-				associated_token_account.Create(associated_token_account.CreateParam{
-					Funder:                 feePayer.PublicKey,
-					Owner:                  alice.PublicKey,
-					Mint:                   mintPubkey,
-					AssociatedTokenAccount: ata,
-				}),
-			},
+			Instructions:    []types.Instruction{createATA},
 		}),
 		Signers: []types.Account{feePayer},
 	})
@@ -3,10 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
+
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/common"
 	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
-	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/config"
 )
 
 type Token struct {
@@ -17,8 +20,15 @@ type Token struct {
 }
 
 func main() {
-	c := client.NewClient(rpc.MainnetRPCEndpoint)
-	token, err := newToken(c, "So11111111111111111111111111111111111111112")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	token, err := newToken(c, cfg.MintAddress("So11111111111111111111111111111111111111112"))
 	fmt.Println(token, err)
 }
 
@@ -3,19 +3,27 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
 	"log"
+
+	"github.com/blocto/solana-go-sdk/program/token"
+
+	"solana-starter/pkg/config"
 )
 
-var mintPubkey = common.PublicKeyFromString("gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT")
+// defaultMint is the mint created by create_mint, used unless another is configured.
+const defaultMint = "gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT"
 
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	getAccountInfoResponse, err := c.GetAccountInfo(context.TODO(), mintPubkey.ToBase58())
+	getAccountInfoResponse, err := c.GetAccountInfo(context.TODO(), cfg.MintAddress(defaultMint))
 	if err != nil {
 		log.Fatalf("failed to get account info, err: %v", err)
 	}
@@ -2,19 +2,31 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 
-	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/config"
 )
 
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// token account address
-	getAccountInfoResponse, err := c.GetAccountInfo(context.TODO(), "BdEcBm46DWCEBFXVHwXhW76RLqzyCpaiJMxgveL8dLEm")
+	address := flag.Arg(0)
+	if address == "" {
+		address = "BdEcBm46DWCEBFXVHwXhW76RLqzyCpaiJMxgveL8dLEm"
+	}
+	getAccountInfoResponse, err := c.GetAccountInfo(context.TODO(), address)
 	if err != nil {
 		log.Fatalf("failed to get account info, err: %v", err)
 	}
@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
+
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/common"
 	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
-	"github.com/blocto/solana-go-sdk/rpc"
-	"log"
+
+	"solana-starter/pkg/config"
 )
 
 const (
@@ -36,9 +38,16 @@ func GetTokenMetadata(c *client.Client, mintAddress string) (*token_metadata.Met
 }
 
 func main() {
-	c := client.NewClient(rpc.MainnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	tokenMetadata, err := GetTokenMetadata(c, USDCMintAddress)
+	tokenMetadata, err := GetTokenMetadata(c, cfg.MintAddress(USDCMintAddress))
 	if err != nil {
 		log.Fatalf("failed to retrieve token metadata: %v", err)
 	}
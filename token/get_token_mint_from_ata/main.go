@@ -2,18 +2,38 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"log"
+
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/common"
 	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/config"
 )
 
 func main() {
-	c := client.NewClient(rpc.MainnetRPCEndpoint)
-	mint, err := getTokenMintFromATA(c, common.PublicKeyFromString("3mHBG2nm6Y9inWayRE7qgfeYMocaoZScfAxizWf19zrS"))
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	address := flag.Arg(0)
+	if address == "" {
+		address = "3mHBG2nm6Y9inWayRE7qgfeYMocaoZScfAxizWf19zrS"
+	}
+	ata, err := config.PublicKey(address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mint, err := getTokenMintFromATA(c, ata)
 	if err != nil {
-		return
+		log.Fatal(err)
 	}
 	fmt.Println(mint)
 }
@@ -3,26 +3,51 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
-	"github.com/blocto/solana-go-sdk/types"
 	"log"
-)
-
-// GLndC8XmRT5o6oBLwn8scDNvFY5MuX78wxJQsW5tXctk
-var feePayer, _ = types.AccountFromBase58("D8i1DFhgxWkBC52kRUtDRkZL5J5bUDJXssJtsehWYT51txphk8ipWe8goFKJt6638vAmEHVxdovsjmfiHPvKPbS")
 
-// HcNCxoni2Ln5si48s1w8r5TRVH296RQ1MzKeM9FctdPg
-var alice, _ = types.AccountFromBase58("5ob5v9uGyJstuENC4pu7ScCdkCPiAZXqFLhu3qUoHB8uePLchCpPmgWyXPZ24NxLBD8dUP6UFNNYXKsFJtFKie74")
+	"github.com/blocto/solana-go-sdk/types"
 
-var mintPubkey = common.PublicKeyFromString("gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT")
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
+)
 
-var aliceTokenATAPubkey = common.PublicKeyFromString("BdEcBm46DWCEBFXVHwXhW76RLqzyCpaiJMxgveL8dLEm")
+// defaultMint is the mint created by create_mint, used unless another is configured.
+const defaultMint = "gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT"
 
+// mint_to mints 1 token to alice's (the configured authority's) associated token account.
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, err := cfg.AuthorityAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mintPubkey, err := config.PublicKey(cfg.MintAddress(defaultMint))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the token account is created first if alice has none
+	instructions, err := tokenops.MintTo(tokenops.MintToParam{
+		Payer:     feePayer.PublicKey,
+		Owner:     alice.PublicKey,
+		Mint:      mintPubkey,
+		Authority: alice.PublicKey,
+		Amount:    1e8,
+		Decimals:  8,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	res, err := c.GetLatestBlockhash(context.Background())
 	if err != nil {
@@ -33,16 +58,7 @@ func main() {
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        feePayer.PublicKey,
 			RecentBlockhash: res.Blockhash,
-			Instructions: []types.Instruction{
-				token.MintToChecked(token.MintToCheckedParam{
-					Mint:     mintPubkey,
-					Auth:     alice.PublicKey,
-					Signers:  []common.PublicKey{},
-					To:       aliceTokenATAPubkey,
-					Amount:   1e8,
-					Decimals: 8,
-				}),
-			},
+			Instructions:    instructions,
 		}),
 		Signers: []types.Account{feePayer, alice},
 	})
@@ -55,7 +71,7 @@ func main() {
 		log.Fatalf("send raw tx error, err: %v\n", err)
 	}
 
-	fmt.Printf("check tx at: https://explorer.solana.com/tx/%s?cluster=devnet\n", txhash)
+	fmt.Printf("check tx at: %s\n", cfg.ExplorerTxURL(txhash))
 }
 
 /*
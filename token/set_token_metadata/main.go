@@ -7,26 +7,46 @@ import (
 
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/metaplex/token_metadata"
-	"github.com/blocto/solana-go-sdk/rpc"
 	"github.com/blocto/solana-go-sdk/types"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
 )
 
-// Fee payer account
-var feePayer, _ = types.AccountFromBase58("D8i1DFhgxWkBC52kRUtDRkZL5J5bUDJXssJtsehWYT51txphk8ipWe8goFKJt6638vAmEHVxdovsjmfiHPvKPbS")
+// Token Mint Pubkey, used unless another is configured
+const defaultMint = "gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT"
 
-// Alice account
-var alice, _ = types.AccountFromBase58("5ob5v9uGyJstuENC4pu7ScCdkCPiAZXqFLhu3qUoHB8uePLchCpPmgWyXPZ24NxLBD8dUP6UFNNYXKsFJtFKie74")
+// Function to set token metadata: it creates the metadata account, or updates the fields given if one exists
+func setTokenMetadata(cfg *config.Config, c *client.Client, mintPubkey common.PublicKey, name, symbol, uri string) error {
+	// Fee payer account
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		return err
+	}
 
-// Token Mint Pubkey
-var mintPubkey = common.PublicKeyFromString("gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT")
+	// Mint authority account (alice), which becomes the update authority
+	alice, err := cfg.AuthorityAccount()
+	if err != nil {
+		return err
+	}
 
-// Function to set token metadata
-func setTokenMetadata(c *client.Client, mintPubkey common.PublicKey, data token_metadata.DataV2) error {
-	// Get the metadata account for the mint
-	metadataAccount, err := token_metadata.GetTokenMetaPubkey(mintPubkey)
+	// Fetch the current metadata of the mint, nil if it has none yet
+	_, metadata, err := tokenops.GetMetadata(context.Background(), c, mintPubkey)
+	if err != nil {
+		return err
+	}
+
+	// Create or update the metadata account
+	instruction, _, err := tokenops.SetMetadata(metadata, tokenops.SetMetadataParam{
+		Mint:      mintPubkey,
+		Payer:     feePayer.PublicKey,
+		Authority: alice.PublicKey,
+		Name:      name,
+		Symbol:    symbol,
+		URI:       uri,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get metadata account: %v", err)
+		return err
 	}
 
 	// Fetch the latest blockhash for the transaction
@@ -35,22 +55,12 @@ func setTokenMetadata(c *client.Client, mintPubkey common.PublicKey, data token_
 		return fmt.Errorf("failed to get recent blockhash: %v", err)
 	}
 
-	// Create Metadata Account using CreateMetadataAccountV2
-	createMetadataAccount := token_metadata.CreateMetadataAccountV3(token_metadata.CreateMetadataAccountV3Param{
-		Metadata:      metadataAccount,
-		Mint:          mintPubkey,
-		MintAuthority: alice.PublicKey,
-		Payer:         feePayer.PublicKey,
-		Data:          data,
-		IsMutable:     true,
-	})
-
 	// Create a transaction with the given instructions
 	tx, err := types.NewTransaction(types.NewTransactionParam{
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        feePayer.PublicKey,
 			RecentBlockhash: res.Blockhash,
-			Instructions:    []types.Instruction{createMetadataAccount},
+			Instructions:    []types.Instruction{instruction},
 		}),
 		Signers: []types.Account{feePayer, alice}, // Both feePayer and mintAuthority (alice) need to sign
 	})
@@ -65,26 +75,30 @@ func setTokenMetadata(c *client.Client, mintPubkey common.PublicKey, data token_
 	}
 
 	// Output transaction explorer link
-	fmt.Printf("Check the transaction at: https://explorer.solana.com/tx/%s?cluster=devnet\n", sig)
+	fmt.Printf("Check the transaction at: %s\n", cfg.ExplorerTxURL(sig))
 
 	return nil
 }
 
 func main() {
-	// Create a new Solana client pointing to the Devnet cluster
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
-
-	// Token metadata to be set
-	metadataData := token_metadata.DataV2{
-		Name:                 "Cool token",            // Token name
-		Symbol:               "COOL",                  // Token symbol
-		Uri:                  "https://cooltoken.com", // Token URI
-		SellerFeeBasisPoints: 0,
-		Creators:             nil,
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Create a new Solana client pointing to the configured cluster
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mintPubkey, err := config.PublicKey(cfg.MintAddress(defaultMint))
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// Attempt to set the token metadata
-	err := setTokenMetadata(c, mintPubkey, metadataData)
+	// Attempt to set the token name, symbol and URI
+	err = setTokenMetadata(cfg, c, mintPubkey, "Cool token", "COOL", "https://cooltoken.com")
 	if err != nil {
 		log.Fatalf("set token metadata error: %v", err)
 	}
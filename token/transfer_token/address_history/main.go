@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/history"
+	"solana-starter/pkg/output"
+)
+
+func main() {
+	before := flag.String("before", "", "start before this signature instead of the latest transaction")
+	until := flag.String("until", "", "stop when this signature is reached")
+	limit := flag.Int("limit", 100, "maximum number of transactions to decode, 0 for all")
+	format := flag.String("format", string(output.FormatText), fmt.Sprintf("output format, one of %v", output.Formats))
+	out := flag.String("o", "", "file to write transfers to instead of stdout")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputFormat, err := output.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flag.NArg() != 1 {
+		log.Fatalf("usage: address_history [flags] <wallet or token account>")
+	}
+	address := flag.Arg(0)
+
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	crawler := history.New(c, decoder.New(c))
+
+	records := make(chan history.Record)
+	type result struct {
+		last string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		last, err := crawler.Crawl(context.Background(), address, history.Config{
+			Before: *before,
+			Until:  *until,
+			Limit:  *limit,
+		}, records)
+		done <- result{last, err}
+		close(records)
+	}()
+
+	w, err := output.Create(*out, outputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for record := range records {
+		if err := w.Write(output.Record{Signature: record.Signature, Slot: record.Slot, BlockTime: record.BlockTime, Transfer: record.Transfer}); err != nil {
+			log.Fatalf("write error, err: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("write error, err: %v", err)
+	}
+	res := <-done
+	if res.err != nil {
+		log.Fatalf("crawl error, err: %v (resume with -before %s)", res.err, res.last)
+	}
+	if res.last != "" {
+		log.Printf("oldest signature decoded: %s", res.last)
+	}
+}
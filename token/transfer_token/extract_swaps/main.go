@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/swap"
+)
+
+func main() {
+	txHash := flag.String("tx", "4yoaptWrZcNuyPujYTCT3xtydveKa6MLxJr9v4Ypmr9uMpLRUubj2xupL3F8KRQwKVi2YLvetS34sQWYw9R4YupF", "transaction signature")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	interpreter := swap.New(decoder.New(c))
+
+	swaps, err := interpreter.DecodeSignature(context.Background(), *txHash)
+	if errors.Is(err, decoder.ErrTransactionFailed) {
+		log.Fatalf("transaction %s failed, nothing was swapped: %v", *txHash, err)
+	}
+	if err != nil {
+		log.Fatalf("failed to decode swaps, err: %v", err)
+	}
+	for _, s := range swaps {
+		program := s.ProgramName
+		if program == "" {
+			program = s.ProgramID
+		}
+		if s.Ambiguous {
+			fmt.Printf("%s via %s: ambiguous, net changes %+v\n", s.Trader, program, s.Legs)
+			continue
+		}
+		fmt.Printf("%s swapped %s %s for %s %s via %s (price %s, multi-hop %t)\n",
+			s.Trader, s.Input.UiAmount, s.Input.Symbol, s.Output.UiAmount, s.Output.Symbol, program, s.Price, s.MultiHop)
+	}
+}
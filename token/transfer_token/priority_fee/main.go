@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+)
+
+func main() {
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flag.NArg() == 0 {
+		log.Fatalf("usage: priority_fee <signature>...")
+	}
+
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, signature := range flag.Args() {
+		tx, err := c.GetTransaction(context.Background(), signature)
+		if err != nil {
+			log.Fatalf("failed to get transaction, err: %v", err)
+		}
+		if tx == nil {
+			log.Fatalf("transaction %s not found", signature)
+		}
+		fees, err := decoder.ComputeFees(tx)
+		if err != nil {
+			log.Fatalf("failed to compute fees, err: %v", err)
+		}
+
+		fmt.Printf("%s\n", signature)
+		fmt.Printf("  base fee:     %d lamports (%s SOL) for %d signatures\n", fees.BaseFee, fees.UiBaseFee, fees.Signatures)
+		fmt.Printf("  priority fee: %d lamports (%s SOL) at %d micro-lamports per unit, limit %d\n",
+			fees.PriorityFee, fees.UiPriorityFee, fees.ComputeUnitPrice, fees.ComputeUnitLimit)
+		fmt.Printf("  total fee:    %d lamports (%s SOL)\n", fees.TotalFee, fees.UiTotalFee)
+		if fees.ComputeUnitsConsumed != nil {
+			fmt.Printf("  consumed:     %d units, priority fee on consumed units %d lamports (%s SOL)\n",
+				*fees.ComputeUnitsConsumed, fees.ConsumedPriorityFee, fees.UiConsumedPriorityFee)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"path/filepath"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/fixture"
+)
+
+// save_fixture saves transactions and the token info they need as decoder fixtures, fetched from the configured
+// cluster. TestDecodeGolden in pkg/decoder checks them once they are listed there.
+func main() {
+	dir := flag.String("dir", "pkg/decoder/testdata", "fixture directory")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if flag.NArg() == 0 {
+		log.Fatalf("usage: save_fixture [flags] <signature>...")
+	}
+
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Decode each transaction online once so the token info it needs ends up in the fixture, without expiry
+	tokens, err := decoder.LoadTokenCache(filepath.Join(*dir, fixture.TokensFile), 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d := decoder.New(c, decoder.WithTokenCache(tokens))
+
+	ctx := context.Background()
+	for _, signature := range flag.Args() {
+		if err := fixture.SaveTransaction(ctx, c, *dir, signature); err != nil {
+			log.Fatal(err)
+		}
+		if _, err := d.DecodeSignatureEvents(ctx, signature); err != nil {
+			log.Fatalf("failed to decode %s: %v", signature, err)
+		}
+		log.Printf("saved %s", signature)
+	}
+	if err := tokens.Save(); err != nil {
+		log.Fatal(err)
+	}
+}
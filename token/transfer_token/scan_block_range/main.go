@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/output"
+	"solana-starter/pkg/scanner"
+)
+
+func main() {
+	startSlot := flag.Uint64("start", 0, "first slot to scan")
+	endSlot := flag.Uint64("end", 0, "last slot to scan, inclusive")
+	concurrency := flag.Int("concurrency", scanner.DefaultConcurrency, "blocks fetched in parallel")
+	checkpoint := flag.String("checkpoint", "", "file to resume from and record progress in")
+	format := flag.String("format", string(output.FormatText), fmt.Sprintf("output format, one of %v", output.Formats))
+	out := flag.String("o", "", "file to write transfers to instead of stdout")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputFormat, err := output.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *endSlot < *startSlot {
+		log.Fatalf("end slot %d is before start slot %d", *endSlot, *startSlot)
+	}
+
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []scanner.Option{scanner.WithConcurrency(*concurrency)}
+	if *checkpoint != "" {
+		opts = append(opts, scanner.WithCheckpoint(scanner.FileCheckpoint(*checkpoint)))
+	}
+	s := scanner.New(c, decoder.New(c), opts...)
+
+	records := make(chan scanner.Record)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Scan(context.Background(), *startSlot, *endSlot, records)
+		close(records)
+	}()
+
+	w, err := output.Create(*out, outputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for record := range records {
+		if err := w.Write(output.Record{Signature: record.Signature, Slot: record.Slot, Transfer: record.Transfer}); err != nil {
+			log.Fatalf("write error, err: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("write error, err: %v", err)
+	}
+	if err := <-errc; err != nil {
+		log.Fatalf("scan error, err: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/blocto/solana-go-sdk/common"
+	"github.com/blocto/solana-go-sdk/rpc"
+
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/decoder"
+	"solana-starter/pkg/output"
+	"solana-starter/pkg/stream"
+)
+
+func main() {
+	address := flag.String("address", common.TokenProgramID.ToBase58(), "wallet, token account or program to watch")
+	wait := flag.String("wait", "", "wait for this signature to be confirmed and print its transfers instead of watching")
+	format := flag.String("format", string(output.FormatText), fmt.Sprintf("output format, one of %v", output.Formats))
+	out := flag.String("o", "", "file to write transfers to instead of stdout")
+	cfg, err := config.Parse(config.Config{Cluster: config.Mainnet})
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputFormat, err := output.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	wsURL := cfg.WSURL
+	if wsURL == "" {
+		if wsURL, err = stream.WebsocketEndpoint(cfg.RPCURL); err != nil {
+			log.Fatal(err)
+		}
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	d := decoder.New(c, decoder.WithCommitment(rpc.CommitmentConfirmed))
+	s := stream.New(wsURL, c, d)
+
+	w, err := output.Create(*out, outputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *wait != "" {
+		transfers, err := s.WaitSignature(ctx, *wait)
+		if err != nil {
+			log.Fatalf("wait error, err: %v", err)
+		}
+		for _, transfer := range transfers {
+			if err := w.Write(output.Record{Signature: *wait, Transfer: transfer}); err != nil {
+				log.Fatalf("write error, err: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			log.Fatalf("write error, err: %v", err)
+		}
+		return
+	}
+
+	records := make(chan stream.Record)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Watch(ctx, *address, records)
+		close(records)
+	}()
+
+	for record := range records {
+		if err := w.Write(output.Record{Signature: record.Signature, Slot: record.Slot, Transfer: record.Transfer}); err != nil {
+			log.Fatalf("write error, err: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("write error, err: %v", err)
+	}
+	if err := <-done; err != nil && ctx.Err() == nil {
+		log.Fatalf("stream error, err: %v", err)
+	}
+}
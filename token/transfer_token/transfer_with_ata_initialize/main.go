@@ -3,64 +3,64 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/blocto/solana-go-sdk/program/associated_token_account"
-	"github.com/mr-tron/base58"
 	"log"
 
-	"github.com/blocto/solana-go-sdk/client"
-	"github.com/blocto/solana-go-sdk/common"
-	"github.com/blocto/solana-go-sdk/program/token"
-	"github.com/blocto/solana-go-sdk/rpc"
 	"github.com/blocto/solana-go-sdk/types"
-)
-
-// GLndC8XmRT5o6oBLwn8scDNvFY5MuX78wxJQsW5tXctk
-var feePayer, _ = types.AccountFromBase58("D8i1DFhgxWkBC52kRUtDRkZL5J5bUDJXssJtsehWYT51txphk8ipWe8goFKJt6638vAmEHVxdovsjmfiHPvKPbS")
 
-// HcNCxoni2Ln5si48s1w8r5TRVH296RQ1MzKeM9FctdPg
-var alice, _ = types.AccountFromBase58("5ob5v9uGyJstuENC4pu7ScCdkCPiAZXqFLhu3qUoHB8uePLchCpPmgWyXPZ24NxLBD8dUP6UFNNYXKsFJtFKie74")
-
-var mintPubkey = common.PublicKeyFromString("gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT")
+	"solana-starter/pkg/config"
+	"solana-starter/pkg/tokenops"
+)
 
-var aliceTokenATAPubkey = common.PublicKeyFromString("BdEcBm46DWCEBFXVHwXhW76RLqzyCpaiJMxgveL8dLEm")
+// defaultMint is the mint created by create_mint, used unless another is configured.
+const defaultMint = "gYqzga5v1RoVWxtfXizHuoyxUpTnzf9WyrXftTkDfpT"
 
 func main() {
-	c := client.NewClient(rpc.DevnetRPCEndpoint)
-
+	cfg, err := config.Parse(config.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	c, err := cfg.Client()
+	if err != nil {
+		log.Fatal(err)
+	}
+	feePayer, err := cfg.FeePayerAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, err := cfg.AuthorityAccount()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mintPubkey, err := config.PublicKey(cfg.MintAddress(defaultMint))
+	if err != nil {
+		log.Fatal(err)
+	}
 	res, err := c.GetLatestBlockhash(context.Background())
 	if err != nil {
 		log.Fatalf("get recent block hash error, err: %v\n", err)
 	}
 
 	newAccount := types.NewAccount()
-	log.Println("new account:", newAccount.PublicKey.ToBase58(), base58.Encode(newAccount.PrivateKey))
+	log.Println("new account:", newAccount.PublicKey.ToBase58())
 
-	ata, _, err := common.FindAssociatedTokenAddress(newAccount.PublicKey, mintPubkey)
+	// creates the new account's token account, then transfers from alice's
+	instructions, err := tokenops.Transfer(tokenops.TransferParam{
+		Payer:    feePayer.PublicKey,
+		From:     alice.PublicKey,
+		To:       newAccount.PublicKey,
+		Mint:     mintPubkey,
+		Amount:   1e7,
+		Decimals: 8,
+	})
 	if err != nil {
-		log.Fatalf("find ata error, err: %v", err)
+		log.Fatal(err)
 	}
 
 	tx, err := types.NewTransaction(types.NewTransactionParam{
 		Message: types.NewMessage(types.NewMessageParam{
 			FeePayer:        feePayer.PublicKey,
 			RecentBlockhash: res.Blockhash,
-			Instructions: []types.Instruction{
-				associated_token_account.Create(associated_token_account.CreateParam{
-					Funder:                 feePayer.PublicKey,
-					Owner:                  newAccount.PublicKey,
-					Mint:                   mintPubkey,
-					AssociatedTokenAccount: ata,
-				}),
-				token.TransferChecked(token.TransferCheckedParam{
-					From:     aliceTokenATAPubkey,
-					To:       ata,
-					Mint:     mintPubkey,
-					Auth:     alice.PublicKey,
-					Signers:  []common.PublicKey{},
-					Amount:   1e7,
-					Decimals: 8,
-				}),
-			},
+			Instructions:    instructions,
 		}),
 		Signers: []types.Account{feePayer, alice},
 	})
@@ -73,10 +73,10 @@ func main() {
 		log.Fatalf("send raw tx error, err: %v\n", err)
 	}
 
-	fmt.Printf("check tx at: https://explorer.solana.com/tx/%s?cluster=devnet\n", txhash)
+	fmt.Printf("check tx at: %s\n", cfg.ExplorerTxURL(txhash))
 }
 
 /*
-2024/09/06 11:37:28 new account: DfHYap9MpUyNjEkVKUC8jwwy7TrsPWrWnaAMzdZUroAg 59UvmLfepKgEoUdGWjjachF3neFuqRZkrmo6BgBn1EMWg7squTRZxuCB3L9zRT8bBzdU9QaLHhR5byEroVFx4fC4
+2024/09/06 11:37:28 new account: DfHYap9MpUyNjEkVKUC8jwwy7TrsPWrWnaAMzdZUroAg
 check tx at: https://explorer.solana.com/tx/4Wo87ndvevGXEprhu4UwBHC3GxpFQiW925uomUFt2yVHk1J61rzZXcSmjTPn73XyhA3TncoXUNZAJxpxkguD1jtF?cluster=devnet
 */